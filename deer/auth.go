@@ -0,0 +1,76 @@
+package deer
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+)
+
+// Auth defines http check authentication.
+// Secrets are never inlined, they are read from env variables or files.
+type Auth struct {
+	// label
+	Type string `hcl:"type,label"`
+	// body
+	Username     string `hcl:"username,optional"`
+	PasswordEnv  string `hcl:"password_env,optional"`
+	PasswordFile string `hcl:"password_file,optional"`
+	TokenEnv     string `hcl:"token_env,optional"`
+	TokenFile    string `hcl:"token_file,optional"`
+	CertFile     string `hcl:"cert_file,optional"`
+	KeyFile      string `hcl:"key_file,optional"`
+	CAFile       string `hcl:"ca_file,optional"`
+}
+
+// authorization returns value of Authorization header for basic and bearer auth.
+func (a *Auth) authorization() (string, error) {
+	switch a.Type {
+	case "basic":
+		if a.Username == "" {
+			return "", fmt.Errorf("Basic auth requires username")
+		}
+		password, err := readSecret(a.PasswordEnv, a.PasswordFile)
+		if err != nil {
+			return "", fmt.Errorf("Basic auth password: %v", err)
+		}
+		creds := base64.StdEncoding.EncodeToString([]byte(a.Username + ":" + password))
+		return "Basic " + creds, nil
+
+	case "bearer":
+		token, err := readSecret(a.TokenEnv, a.TokenFile)
+		if err != nil {
+			return "", fmt.Errorf("Bearer auth token: %v", err)
+		}
+		return "Bearer " + token, nil
+	}
+
+	return "", fmt.Errorf("Invalid auth type")
+}
+
+// tlsConfig returns tls config with client certificate and custom CA.
+func (a *Auth) tlsConfig() (*tls.Config, error) {
+	if a.CertFile == "" || a.KeyFile == "" {
+		return nil, fmt.Errorf("TLS auth requires cert_file and key_file")
+	}
+
+	cert, err := tls.LoadX509KeyPair(a.CertFile, a.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("TLS auth: %v", err)
+	}
+	cfg := &tls.Config{Certificates: []tls.Certificate{cert}}
+
+	if a.CAFile != "" {
+		ca, err := ioutil.ReadFile(a.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("TLS auth: %v", err)
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("TLS auth: no certificates found in %s", a.CAFile)
+		}
+	}
+
+	return cfg, nil
+}
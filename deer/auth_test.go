@@ -0,0 +1,128 @@
+package deer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/franela/goblin"
+)
+
+func TestAuth(t *testing.T) {
+	g := goblin.Goblin(t)
+	g.Describe("Auth", func() {
+		var (
+			srv *httptest.Server
+			dir string
+		)
+
+		g.Before(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "ohdeer")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			srv = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				client := ""
+				if len(r.TLS.PeerCertificates) > 0 {
+					client = r.TLS.PeerCertificates[0].Subject.CommonName
+				}
+				fmt.Fprintf(w, "%s|%s", r.Header.Get("Authorization"), client)
+			}))
+			srv.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+			srv.StartTLS()
+		})
+
+		g.After(func() {
+			srv.Close()
+			os.RemoveAll(dir)
+		})
+
+		do := func(h *HTTPCheck) string {
+			if err := h.Validate(); err != nil {
+				return err.Error()
+			}
+			resp := h.Request().Do(context.Background(), srv.URL, time.Second)
+			if resp.Err != nil {
+				return resp.Err.Error()
+			}
+			return string(resp.Body)
+		}
+
+		check := func(auth ...Auth) *HTTPCheck {
+			caFile := filepath.Join(dir, "ca.pem")
+			writePEM(caFile, "CERTIFICATE", srv.Certificate().Raw)
+
+			return &HTTPCheck{
+				IntervalSec:  1,
+				TimeoutSec:   1,
+				Addr:         srv.URL,
+				Auth:         append(auth, Auth{Type: "tls", CertFile: filepath.Join(dir, "client.pem"), KeyFile: filepath.Join(dir, "client.key"), CAFile: caFile}),
+				Expectations: []Expect{{Subject: "status", Inclusion: []int{200}}},
+			}
+		}
+
+		g.It("Sends basic auth with password from env and client certificate", func() {
+			writeClientCert(dir, "monitor")
+			os.Setenv("OHDEER_TEST_PASSWORD", "secret")
+			defer os.Unsetenv("OHDEER_TEST_PASSWORD")
+
+			body := do(check(Auth{Type: "basic", Username: "ohdeer", PasswordEnv: "OHDEER_TEST_PASSWORD"}))
+
+			g.Assert(body).Equal("Basic b2hkZWVyOnNlY3JldA==|monitor")
+		})
+
+		g.It("Sends bearer token from file", func() {
+			writeClientCert(dir, "monitor")
+			tokenFile := filepath.Join(dir, "token")
+			ioutil.WriteFile(tokenFile, []byte("t0k3n\n"), 0600)
+
+			body := do(check(Auth{Type: "bearer", TokenFile: tokenFile}))
+
+			g.Assert(body).Equal("Bearer t0k3n|monitor")
+		})
+
+		g.It("Fails when env variable is missing", func() {
+			writeClientCert(dir, "monitor")
+
+			body := do(check(Auth{Type: "bearer", TokenEnv: "OHDEER_TEST_MISSING"}))
+
+			g.Assert(body).Equal("Bearer auth token: Env variable OHDEER_TEST_MISSING is not set")
+		})
+	})
+}
+
+func writeClientCert(dir, name string) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, _ := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	keyDer, _ := x509.MarshalECPrivateKey(key)
+
+	writePEM(filepath.Join(dir, "client.pem"), "CERTIFICATE", der)
+	writePEM(filepath.Join(dir, "client.key"), "EC PRIVATE KEY", keyDer)
+}
+
+func writePEM(path, typ string, der []byte) {
+	ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0600)
+}
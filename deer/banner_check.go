@@ -0,0 +1,108 @@
+package deer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+)
+
+// BannerCheck defines generic text protocol type check.
+// It connects, optionally sends data and reads a single line reply.
+type BannerCheck struct {
+	ref
+
+	// body
+	IntervalSec     float64  `hcl:"interval"`
+	TimeoutSec      uint64   `hcl:"timeout"`
+	JitterSec       uint64   `hcl:"jitter,optional"`
+	Retries         uint64   `hcl:"retries,optional"`
+	RetryDelaySec   uint64   `hcl:"retry_delay,optional"`
+	ConfirmFailures uint64   `hcl:"confirm_failures,optional"`
+	Addr            string   `hcl:"addr"`
+	Send            string   `hcl:"send,optional"`
+	Expectations    []Expect `hcl:"expect,block"`
+}
+
+// Validate ensures correct values are set for banner check.
+func (b *BannerCheck) Validate() error {
+	if err := validateSchedule(b.IntervalSec, b.TimeoutSec, b.JitterSec, b.retryPolicy()); err != nil {
+		return err
+	}
+
+	if len(b.Addr) == 0 {
+		return fmt.Errorf("Addr cannot be empty")
+	}
+	if _, _, err := net.SplitHostPort(b.Addr); err != nil {
+		return fmt.Errorf("Addr must be in host:port format")
+	}
+
+	if len(b.Expectations) == 0 {
+		return fmt.Errorf("At least one expectation for banner check is required")
+	}
+	for _, expect := range b.Expectations {
+		switch expect.Subject {
+		case "reply":
+			if err := expect.validateMatcher("Reply"); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("Invalid expectation subject")
+		}
+	}
+
+	return nil
+}
+
+// Interval returns how often check should be run.
+func (b *BannerCheck) Interval() time.Duration {
+	return seconds(b.IntervalSec)
+}
+
+// Jitter returns maximum random delay added to each run.
+func (b *BannerCheck) Jitter() time.Duration {
+	return time.Duration(b.JitterSec) * time.Second
+}
+
+// target returns addr, from which check ID is derived.
+func (b *BannerCheck) target() string {
+	return b.Addr
+}
+
+// retryPolicy returns how failed probes are retried.
+func (b *BannerCheck) retryPolicy() retryPolicy {
+	return retryPolicy{b.Retries, b.RetryDelaySec, b.ConfirmFailures}
+}
+
+// RunFn returns task function to run check.
+func (b *BannerCheck) RunFn(s Store) func(context.Context) {
+	store := s
+
+	return func(ctx context.Context) {
+		b.ref.run(ctx, store, b.retryPolicy(), "", func() CheckResult {
+			now := time.Now()
+			resp := b.Dial(ctx, time.Duration(b.TimeoutSec)*time.Second)
+
+			return b.ref.tcpResult(now, resp, b.Verify(resp))
+		})
+	}
+}
+
+// Dial connects to the address, sends data (if set) and reads the reply.
+func (b *BannerCheck) Dial(ctx context.Context, timeout time.Duration) *TCPResponse {
+	return exchange(ctx, b.Addr, []byte(b.Send), true, timeout)
+}
+
+// Check verifies if check is valid or not.
+func (b *BannerCheck) Check(resp *TCPResponse) bool {
+	return b.Verify(resp) == nil
+}
+
+// Verify returns the reason why check is not valid or nil when it passes.
+func (b *BannerCheck) Verify(resp *TCPResponse) error {
+	if resp.Err != nil {
+		return resp.Err
+	}
+
+	return verifyBanner(b.Expectations, "reply", "Reply", resp.Banner)
+}
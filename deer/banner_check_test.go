@@ -0,0 +1,83 @@
+package deer
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/franela/goblin"
+)
+
+func TestBannerCheck(t *testing.T) {
+	g := goblin.Goblin(t)
+	g.Describe("BannerCheck", func() {
+		var ln net.Listener
+
+		g.Before(func() {
+			var err error
+			ln, err = net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			go func() {
+				for {
+					conn, err := ln.Accept()
+					if err != nil {
+						return
+					}
+					go func(conn net.Conn) {
+						defer conn.Close()
+						conn.SetDeadline(time.Now().Add(100 * time.Millisecond))
+						line, err := bufio.NewReader(conn).ReadString('\n')
+						conn.SetDeadline(time.Time{})
+						if err != nil {
+							conn.Write([]byte("* OK IMAP4rev1 ready\r\n"))
+							return
+						}
+						conn.Write([]byte("echo: " + line))
+					}(conn)
+				}
+			}()
+		})
+
+		g.After(func() {
+			ln.Close()
+		})
+
+		g.It("Matches greeting without sending", func() {
+			check := &BannerCheck{
+				Addr:         ln.Addr().String(),
+				Expectations: []Expect{{Subject: "reply", Matches: `^\* OK`}},
+			}
+			resp := check.Dial(context.Background(), time.Second)
+
+			g.Assert(resp.Err).IsNil()
+			g.Assert(resp.Banner).Equal("* OK IMAP4rev1 ready")
+			g.Assert(check.Check(resp)).IsTrue()
+		})
+
+		g.It("Sends data and matches reply", func() {
+			check := &BannerCheck{
+				Addr:         ln.Addr().String(),
+				Send:         "PING\r\n",
+				Expectations: []Expect{{Subject: "reply", Equals: "echo: PING"}},
+			}
+			resp := check.Dial(context.Background(), time.Second)
+
+			g.Assert(resp.Err).IsNil()
+			g.Assert(check.Check(resp)).IsTrue()
+		})
+
+		g.It("Fails when reply does not match", func() {
+			check := &BannerCheck{
+				Addr:         ln.Addr().String(),
+				Send:         "PING\r\n",
+				Expectations: []Expect{{Subject: "reply", Contains: "PONG"}},
+			}
+
+			g.Assert(check.Check(check.Dial(context.Background(), time.Second))).IsFalse()
+		})
+	})
+}
@@ -1,6 +1,10 @@
 package deer
 
-import "time"
+import (
+	"context"
+	"fmt"
+	"time"
+)
 
 // CheckResult is a struct.
 type CheckResult struct {
@@ -11,21 +15,68 @@ type CheckResult struct {
 	Trace      *Trace
 	Error      error
 	StatusCode int
+	Degraded   bool
+	IPVersion  string
+	// Details contains optional check specific details.
+	Details *Details
+}
+
+// DegradedError is returned by verification when check passes
+// but is not performing as expected (e.g. it is too slow).
+type DegradedError struct {
+	Reason error
+}
+
+func (e *DegradedError) Error() string {
+	return e.Reason.Error()
+}
+
+// verdict sets result state based on verification error.
+// Degraded checks are successful but keep the reason.
+func (r *CheckResult) verdict(err error) {
+	_, degraded := err.(*DegradedError)
+
+	r.Success = err == nil || degraded
+	r.Degraded = degraded
+	r.Error = err
 }
 
 // Check is a interface for monitoring checks.
 type Check interface {
 	Validatable
 
-	RunFn(s *Store) func()
-	Check(resp *Response) bool
+	// ID returns check identifier stable across restarts, e.g. "aws/api/http/1f2e3d4c".
+	ID() string
+	// Interval returns how often check should be run.
+	Interval() time.Duration
+	// Jitter returns maximum random delay added to each run.
+	Jitter() time.Duration
+	// RunFn returns task function to run check and save result to store.
+	// In-flight probe is cancelled together with the context.
+	RunFn(s Store) func(context.Context)
+
+	bind(m *Monitor, s *Service, id string)
+	// target returns identifying config (e.g. addr) from which ID is derived.
+	target() string
 }
 
 // Details for checks.
 type Details struct {
-	Trace    *Trace           `json:"trace"`
-	Error    *ErrorDetails    `json:"error,omitempty"`
-	Response *ResponseDetails `json:"response,omitempty"`
+	Trace     *Trace           `json:"trace"`
+	Degraded  bool             `json:"degraded,omitempty"`
+	IPVersion string           `json:"ip_version,omitempty"`
+	Error     *ErrorDetails    `json:"error,omitempty"`
+	Response  *ResponseDetails `json:"response,omitempty"`
+	TCP       *TCPDetails      `json:"tcp,omitempty"`
+	TLS       *TLSDetails      `json:"tls,omitempty"`
+	DNS       *DNSDetails      `json:"dns,omitempty"`
+	Exec      *ExecDetails     `json:"exec,omitempty"`
+	Query     *QueryDetails    `json:"query,omitempty"`
+	GRPC      *GRPCDetails     `json:"grpc,omitempty"`
+	UDP       *UDPDetails      `json:"udp,omitempty"`
+	Steps     []StepDetails    `json:"steps,omitempty"`
+	// Attempts is number of probes made within the run when retries are configured.
+	Attempts int `json:"attempts,omitempty"`
 }
 
 // ErrorDetails contains response error.
@@ -35,5 +86,51 @@ type ErrorDetails struct {
 
 // ResponseDetails contains response error.
 type ResponseDetails struct {
-	StatusCode int `json:"status_code"`
+	StatusCode int        `json:"status_code"`
+	FinalURL   string     `json:"final_url,omitempty"`
+	Redirects  []Redirect `json:"redirects,omitempty"`
+	BodySize   int        `json:"body_size"`
+	BodyHash   string     `json:"body_hash,omitempty"`
+	Changed    bool       `json:"changed,omitempty"`
+}
+
+// TCPDetails contains tcp connection details.
+type TCPDetails struct {
+	Banner string `json:"banner,omitempty"`
+}
+
+// save stores result unless the run was cancelled (e.g. on shutdown),
+// as cancelled probe would be reported as a failure.
+func save(ctx context.Context, s Store, result *CheckResult) {
+	if ctx.Err() != nil {
+		return
+	}
+	s.Save(ctx, result)
+}
+
+// minIntervalSec is the shortest supported check interval.
+const minIntervalSec = 0.1
+
+// validateSchedule ensures check timing is valid. Interval can be fractional number of seconds.
+func validateSchedule(intervalSec float64, timeoutSec, jitterSec uint64, policy retryPolicy) error {
+	switch {
+	case timeoutSec <= 0:
+		return fmt.Errorf("Timeout must be > 0")
+
+	case intervalSec <= 0:
+		return fmt.Errorf("Interval must be > 0")
+
+	case intervalSec < minIntervalSec:
+		return fmt.Errorf("Interval must be >= %g", minIntervalSec)
+
+	case float64(jitterSec) >= intervalSec:
+		return fmt.Errorf("Jitter must be < interval")
+	}
+
+	return policy.validate(seconds(intervalSec), time.Duration(timeoutSec)*time.Second)
+}
+
+// seconds converts number of seconds to duration.
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
 }
@@ -2,15 +2,18 @@ package deer
 
 import (
 	"fmt"
+	"hash/fnv"
 	"io/ioutil"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2/hclsimple"
 )
 
 // Config keeps monitor configuration.
 type Config struct {
-	Server   *Server    `hcl:"server,block"`
-	Monitors []*Monitor `hcl:"monitor,block"`
+	Server   *Server       `hcl:"server,block"`
+	Runner   *RunnerConfig `hcl:"runner,block"`
+	Monitors []*Monitor    `hcl:"monitor,block"`
 }
 
 // Server configuration.
@@ -20,6 +23,12 @@ type Server struct {
 	TLSKeyFile  string `hcl:"tls_key_file"`
 }
 
+// RunnerConfig configures probe execution.
+type RunnerConfig struct {
+	// MaxConcurrency limits number of probes run at the same time, 0 means no limit.
+	MaxConcurrency int `hcl:"max_concurrency,optional"`
+}
+
 // LoadConfig loads and parses config from given path.
 func LoadConfig(path string) (*Config, error) {
 	b, err := ioutil.ReadFile(path)
@@ -42,6 +51,12 @@ func ParseConfig(path string, src []byte) (*Config, error) {
 		if cfg.Server.BindAddress == "" {
 			cfg.Server.BindAddress = ":1820"
 		}
+		if cfg.Runner == nil {
+			cfg.Runner = &RunnerConfig{}
+		}
+		if cfg.Runner.MaxConcurrency < 0 {
+			return nil, fmt.Errorf("Max concurrency must be >= 0")
+		}
 		for _, m := range cfg.Monitors {
 			if len(m.ID) == 0 {
 				return nil, fmt.Errorf("Monitor cannot have empty ID")
@@ -49,6 +64,9 @@ func ParseConfig(path string, src []byte) (*Config, error) {
 			if len(m.Name) == 0 {
 				return nil, fmt.Errorf("Monitor cannot have empty name")
 			}
+			if m.MaxConcurrency < 0 {
+				return nil, fmt.Errorf("Max concurrency in monitor %s must be >= 0", m.ID)
+			}
 
 			for _, s := range m.Services {
 				if len(s.ID) == 0 {
@@ -58,10 +76,25 @@ func ParseConfig(path string, src []byte) (*Config, error) {
 					return nil, fmt.Errorf("Service in monitor %s cannot have empty name", m.ID)
 				}
 
-				for _, h := range s.HTTPChecks {
-					h.ref = ref{Monitor: m, Service: s}
+				// sql checks share implementation and differ only by driver
+				for _, p := range s.Postgres {
+					p.driver = "postgres"
+				}
+				for _, m := range s.MySQL {
+					m.driver = "mysql"
+				}
 
-					if err := h.Validate(); err != nil {
+				seen := map[string]int{}
+				for _, c := range s.Checks() {
+					id := checkID(m, s, c)
+					if n := seen[id]; n > 0 {
+						c.bind(m, s, fmt.Sprintf("%s-%d", id, n))
+					} else {
+						c.bind(m, s, id)
+					}
+					seen[id]++
+
+					if err := c.Validate(); err != nil {
 						return nil, err
 					}
 				}
@@ -72,6 +105,21 @@ func ParseConfig(path string, src []byte) (*Config, error) {
 	return &cfg, err
 }
 
+// checkID derives check ID from its kind and target, e.g. "aws/api/http/1f2e3d4c",
+// so that adding or removing other checks of the service does not change it.
+// Checks with the same kind and target get suffix with their order, e.g. "aws/api/http/1f2e3d4c-1".
+func checkID(m *Monitor, s *Service, c Check) string {
+	h := fnv.New32a()
+	h.Write([]byte(c.target()))
+	return fmt.Sprintf("%s/%s/%s/%08x", m.ID, s.ID, checkKind(c), h.Sum32())
+}
+
+// checkKind returns short check type name, e.g. "http" for *HTTPCheck.
+func checkKind(c Check) string {
+	kind := strings.TrimPrefix(fmt.Sprintf("%T", c), "*deer.")
+	return strings.ToLower(strings.TrimSuffix(kind, "Check"))
+}
+
 // ActiveServices returns list of active services per monitor.
 func (c *Config) ActiveServices() map[string][]string {
 	r := make(map[string][]string, 0)
@@ -1,7 +1,10 @@
 package deer
 
 import (
+	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/franela/goblin"
 )
@@ -70,7 +73,7 @@ func TestParseConfig(t *testing.T) {
 				http := c.Monitors[0].Services[0].HTTPChecks[0]
 				g.Assert(http.Addr).Equal("http://a.local")
 				g.Assert(http.TimeoutSec).Equal(uint64(10))
-				g.Assert(http.IntervalSec).Equal(uint64(100))
+				g.Assert(http.IntervalSec).Equal(float64(100))
 
 			})
 
@@ -84,6 +87,328 @@ func TestParseConfig(t *testing.T) {
 			})
 		})
 
+		g.Describe("HTTP request", func() {
+			g.It("Parses method, headers and body", func() {
+				c, err := ParseConfig("http.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "b" {
+						name = "b"
+						http {
+							interval   = 10
+							timeout    = 10
+							addr       = "http://a.local/graphql"
+							method     = "post"
+							body       = "{\"query\":\"{ health }\"}"
+							user_agent = "Probe/1.0"
+							headers = {
+								Accept         = "application/json"
+								"Content-Type" = "application/json"
+							}
+
+							expect "status" {
+								in = [200]
+							}
+						}
+					}
+				}
+				`))
+
+				g.Assert(err).IsNil()
+				http := c.Monitors[0].Services[0].HTTPChecks[0]
+				g.Assert(http.Method).Equal("POST")
+				g.Assert(http.UserAgent).Equal("Probe/1.0")
+				g.Assert(http.Headers["Content-Type"]).Equal("application/json")
+				g.Assert(string(http.Request().Body)).Equal(`{"query":"{ health }"}`)
+			})
+
+			g.It("Parses auth blocks", func() {
+				os.Setenv("OHDEER_TEST_TOKEN", "t0k3n")
+				defer os.Unsetenv("OHDEER_TEST_TOKEN")
+
+				c, err := ParseConfig("http.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "b" {
+						name = "b"
+						http {
+							interval = 10
+							timeout  = 10
+							addr     = "http://a.local"
+
+							auth "bearer" {
+								token_env = "OHDEER_TEST_TOKEN"
+							}
+
+							expect "status" {
+								in = [200]
+							}
+						}
+					}
+				}
+				`))
+
+				g.Assert(err).IsNil()
+				http := c.Monitors[0].Services[0].HTTPChecks[0]
+				g.Assert(http.Auth[0].Type).Equal("bearer")
+				g.Assert(http.Request().Header.Get("Authorization")).Equal("Bearer t0k3n")
+			})
+
+			g.It("Fails on unknown auth type", func() {
+				_, err := ParseConfig("http.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "b" {
+						name = "b"
+						http {
+							interval = 10
+							timeout  = 10
+							addr     = "http://a.local"
+
+							auth "digest" {}
+
+							expect "status" {
+								in = [200]
+							}
+						}
+					}
+				}
+				`))
+
+				g.Assert(err.Error()).Equal("Invalid auth type")
+			})
+
+			g.It("Parses redirect options", func() {
+				c, err := ParseConfig("http.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "b" {
+						name = "b"
+						http {
+							interval         = 10
+							timeout          = 10
+							addr             = "http://a.local"
+							follow_redirects = true
+
+							expect "final_url" {
+								matches = "^https://"
+							}
+						}
+					}
+				}
+				`))
+
+				g.Assert(err).IsNil()
+				http := c.Monitors[0].Services[0].HTTPChecks[0]
+				g.Assert(http.FollowRedirects).IsTrue()
+				g.Assert(http.MaxRedirects).Equal(10)
+			})
+
+			g.It("Parses ip version", func() {
+				c, err := ParseConfig("http.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "b" {
+						name = "b"
+						http {
+							interval   = 10
+							timeout    = 10
+							addr       = "http://a.local"
+							ip_version = "both"
+
+							expect "status" {
+								in = [200]
+							}
+						}
+					}
+				}
+				`))
+
+				g.Assert(err).IsNil()
+				g.Assert(c.Monitors[0].Services[0].HTTPChecks[0].IPVersion).Equal("both")
+				g.Assert(c.Monitors[0].Services[0].IPVersions()).Equal([]string{"4", "6"})
+			})
+
+			g.It("Fails on invalid ip version", func() {
+				_, err := ParseConfig("http.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "b" {
+						name = "b"
+						http {
+							interval   = 10
+							timeout    = 10
+							addr       = "http://a.local"
+							ip_version = "5"
+
+							expect "status" {
+								in = [200]
+							}
+						}
+					}
+				}
+				`))
+
+				g.Assert(err.Error()).Equal("IP version must be one of 4, 6 or both")
+			})
+
+			g.It("Defaults to GET", func() {
+				c, _ := ParseConfig("http.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "b" {
+						name = "b"
+						http {
+							interval = 10
+							timeout  = 10
+							addr     = "http://a.local"
+
+							expect "status" {
+								in = [200]
+							}
+						}
+					}
+				}
+				`))
+
+				g.Assert(c.Monitors[0].Services[0].HTTPChecks[0].Method).Equal("GET")
+			})
+
+			g.It("Fails when both body and body_file are set", func() {
+				_, err := ParseConfig("http.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "b" {
+						name = "b"
+						http {
+							interval  = 10
+							timeout   = 10
+							addr      = "http://a.local"
+							body      = "{}"
+							body_file = "/tmp/body.json"
+
+							expect "status" {
+								in = [200]
+							}
+						}
+					}
+				}
+				`))
+
+				g.Assert(err.Error()).Equal("Body and body_file cannot be set together")
+			})
+		})
+
+		g.Describe("Body expectations", func() {
+			g.It("Parses body and json expectations", func() {
+				c, err := ParseConfig("http.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "b" {
+						name = "b"
+						http {
+							interval = 10
+							timeout  = 10
+							addr     = "http://a.local/health"
+
+							expect "body" {
+								contains     = "ok"
+								not_contains = "error"
+								matches      = "^\\{"
+							}
+
+							expect "json" {
+								path   = "db"
+								equals = "ok"
+							}
+						}
+					}
+				}
+				`))
+
+				g.Assert(err).IsNil()
+				expects := c.Monitors[0].Services[0].HTTPChecks[0].Expectations
+				g.Assert(expects[0].Contains).Equal("ok")
+				g.Assert(expects[0].NotContains).Equal("error")
+				g.Assert(expects[0].Matches).Equal("^\\{")
+				g.Assert(expects[1].Path).Equal("db")
+				g.Assert(expects[1].Equals).Equal("ok")
+			})
+
+			g.It("Fails on header expectation without name", func() {
+				_, err := ParseConfig("http.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "b" {
+						name = "b"
+						http {
+							interval = 10
+							timeout  = 10
+							addr     = "http://a.local/health"
+
+							expect "header" {
+								present = true
+							}
+						}
+					}
+				}
+				`))
+
+				g.Assert(err.Error()).Equal("Header expectation requires name")
+			})
+
+			g.It("Parses latency expectation", func() {
+				c, err := ParseConfig("http.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "b" {
+						name = "b"
+						http {
+							interval = 10
+							timeout  = 10
+							addr     = "http://a.local/health"
+
+							expect "latency" {
+								total_ms             = 800
+								server_processing_ms = 300
+								degrade              = true
+							}
+						}
+					}
+				}
+				`))
+
+				g.Assert(err).IsNil()
+				expect := c.Monitors[0].Services[0].HTTPChecks[0].Expectations[0]
+				g.Assert(*expect.TotalMs).Equal(800)
+				g.Assert(*expect.ServerProcessingMs).Equal(300)
+				g.Assert(expect.DNSLookupMs == nil).IsTrue()
+				g.Assert(expect.Degrade).IsTrue()
+			})
+
+			g.It("Fails on json expectation without path", func() {
+				_, err := ParseConfig("http.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "b" {
+						name = "b"
+						http {
+							interval = 10
+							timeout  = 10
+							addr     = "http://a.local/health"
+
+							expect "json" {
+								equals = "ok"
+							}
+						}
+					}
+				}
+				`))
+
+				g.Assert(err.Error()).Equal("JSON expectation requires path")
+			})
+		})
+
 		g.Describe("Invalid timeout", func() {
 			g.It("Fails", func() {
 				_, err := ParseConfig("http.hcl", []byte(`
@@ -180,6 +505,924 @@ func TestParseConfig(t *testing.T) {
 			})
 		})
 
+		g.Describe("TCP check", func() {
+			c, err := ParseConfig("tcp.hcl", []byte(`
+			monitor "a" {
+				name = "a"
+				service "db" {
+					name = "DB"
+					tcp {
+						interval = 10
+						timeout  = 2
+						addr     = "localhost:5432"
+
+						expect "connects" {}
+
+						expect "banner" {
+							matches = "^SSH-"
+						}
+					}
+				}
+			}
+			`))
+
+			g.It("Parses tcp check", func() {
+				g.Assert(err).IsNil()
+				tcp := c.Monitors[0].Services[0].TCPChecks[0]
+				g.Assert(tcp.Addr).Equal("localhost:5432")
+				g.Assert(tcp.TimeoutSec).Equal(uint64(2))
+				g.Assert(tcp.IntervalSec).Equal(float64(10))
+				g.Assert(tcp.Expectations[0].Subject).Equal("connects")
+				g.Assert(tcp.Expectations[1].Matches).Equal("^SSH-")
+				g.Assert(len(c.Monitors[0].Services[0].Checks())).Equal(1)
+			})
+
+			g.It("Fails on address without port", func() {
+				_, err := ParseConfig("tcp.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "b" {
+						name = "b"
+						tcp {
+							interval = 10
+							timeout  = 2
+							addr     = "localhost"
+						}
+					}
+				}
+				`))
+
+				g.Assert(err.Error()).Equal("Addr must be in host:port format")
+			})
+		})
+
+		g.Describe("TLS check", func() {
+			g.It("Parses tls check", func() {
+				c, err := ParseConfig("tls.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "web" {
+						name = "Web"
+						tls {
+							interval    = 3600
+							timeout     = 10
+							addr        = "ohdeer.dev:443"
+							server_name = "www.ohdeer.dev"
+
+							expect "cert_days_left" {
+								min = 14
+							}
+						}
+					}
+				}
+				`))
+
+				g.Assert(err).IsNil()
+				tls := c.Monitors[0].Services[0].TLSChecks[0]
+				g.Assert(tls.ServerName).Equal("www.ohdeer.dev")
+				g.Assert(*tls.Expectations[0].Min).Equal(14)
+				g.Assert(tls.Expectations[0].Max == nil).IsTrue()
+			})
+
+			g.It("Fails without bounds", func() {
+				_, err := ParseConfig("tls.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "b" {
+						name = "b"
+						tls {
+							interval = 10
+							timeout  = 2
+							addr     = "ohdeer.dev:443"
+
+							expect "cert_days_left" {}
+						}
+					}
+				}
+				`))
+
+				g.Assert(err.Error()).Equal("Cert days left expectation requires min or max")
+			})
+		})
+
+		g.Describe("DNS check", func() {
+			g.It("Parses dns check", func() {
+				c, err := ParseConfig("dns.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "dns" {
+						name = "DNS"
+						dns {
+							interval = 60
+							timeout  = 5
+							name     = "ohdeer.dev"
+							resolver = "1.1.1.1:53"
+
+							expect "record" {
+								equals = "10.0.0.1"
+							}
+						}
+					}
+				}
+				`))
+
+				g.Assert(err).IsNil()
+				dns := c.Monitors[0].Services[0].DNSChecks[0]
+				g.Assert(dns.Name).Equal("ohdeer.dev")
+				g.Assert(dns.RecordType).Equal("A")
+				g.Assert(dns.Resolver).Equal("1.1.1.1:53")
+				g.Assert(dns.Expectations[0].Equals).Equal("10.0.0.1")
+			})
+
+			g.It("Fails on invalid record type", func() {
+				_, err := ParseConfig("dns.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "b" {
+						name = "b"
+						dns {
+							interval = 60
+							timeout  = 5
+							name     = "ohdeer.dev"
+							type     = "PTR"
+						}
+					}
+				}
+				`))
+
+				g.Assert(err.Error()).Equal("Invalid record type PTR")
+			})
+		})
+
+		g.Describe("Exec check", func() {
+			g.It("Parses exec check", func() {
+				c, err := ParseConfig("exec.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "disk" {
+						name = "Disk"
+						exec {
+							interval = 60
+							timeout  = 10
+							command  = ["sh", "-c", "df --output=pcent / | tail -1"]
+
+							expect "exit_code" {
+								in = [0]
+							}
+						}
+					}
+				}
+				`))
+
+				g.Assert(err).IsNil()
+				exec := c.Monitors[0].Services[0].ExecChecks[0]
+				g.Assert(exec.Command).Equal([]string{"sh", "-c", "df --output=pcent / | tail -1"})
+				g.Assert(exec.Expectations[0].Inclusion).Equal([]int{0})
+			})
+
+			g.It("Fails on empty command", func() {
+				_, err := ParseConfig("exec.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "b" {
+						name = "b"
+						exec {
+							interval = 60
+							timeout  = 10
+							command  = []
+						}
+					}
+				}
+				`))
+
+				g.Assert(err.Error()).Equal("Command cannot be empty")
+			})
+		})
+
+		g.Describe("Database checks", func() {
+			g.It("Parses postgres, mysql and redis checks", func() {
+				os.Setenv("OHDEER_TEST_DSN", "root:secret@tcp(127.0.0.1:3306)/app")
+				defer os.Unsetenv("OHDEER_TEST_DSN")
+
+				c, err := ParseConfig("db.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "db" {
+						name = "DB"
+						postgres {
+							interval = 30
+							timeout  = 5
+							dsn      = "postgres://ohdeer@localhost/deer?sslmode=disable"
+						}
+						mysql {
+							interval = 30
+							timeout  = 5
+							dsn_env  = "OHDEER_TEST_DSN"
+							query    = "SELECT COUNT(*) FROM jobs"
+
+							expect "result" {
+								equals = "0"
+							}
+						}
+						redis {
+							interval = 30
+							timeout  = 5
+							addr     = "localhost:6379"
+						}
+					}
+				}
+				`))
+
+				g.Assert(err).IsNil()
+				s := c.Monitors[0].Services[0]
+				g.Assert(len(s.Checks())).Equal(3)
+				g.Assert(s.Postgres[0].driver).Equal("postgres")
+				g.Assert(s.Postgres[0].Query).Equal("SELECT 1")
+				g.Assert(s.MySQL[0].driver).Equal("mysql")
+				g.Assert(s.MySQL[0].dsn).Equal("root:secret@tcp(127.0.0.1:3306)/app")
+				g.Assert(s.Redis[0].Command).Equal([]string{"PING"})
+			})
+
+			g.It("Fails without dsn", func() {
+				_, err := ParseConfig("db.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "b" {
+						name = "b"
+						postgres {
+							interval = 30
+							timeout  = 5
+						}
+					}
+				}
+				`))
+
+				g.Assert(err.Error()).Equal("DSN: Value, env or file is required")
+			})
+		})
+
+		g.Describe("gRPC check", func() {
+			g.It("Parses grpc check", func() {
+				c, err := ParseConfig("grpc.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "billing" {
+						name = "Billing"
+						grpc {
+							interval    = 10
+							timeout     = 5
+							addr        = "billing.local:443"
+							service     = "billing.v1.Billing"
+							tls         = true
+							server_name = "billing.local"
+						}
+					}
+				}
+				`))
+
+				g.Assert(err).IsNil()
+				grpc := c.Monitors[0].Services[0].GRPCChecks[0]
+				g.Assert(grpc.Service).Equal("billing.v1.Billing")
+				g.Assert(grpc.TLS).IsTrue()
+				g.Assert(grpc.ServerName).Equal("billing.local")
+			})
+		})
+
+		g.Describe("WebSocket check", func() {
+			g.It("Fails on non websocket address", func() {
+				_, err := ParseConfig("ws.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "b" {
+						name = "b"
+						websocket {
+							interval = 10
+							timeout  = 5
+							addr     = "https://rt.local/socket"
+						}
+					}
+				}
+				`))
+
+				g.Assert(err.Error()).Equal("Addr must be ws:// or wss:// url")
+			})
+
+			g.It("Fails on reply expectation without send", func() {
+				_, err := ParseConfig("ws.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "b" {
+						name = "b"
+						websocket {
+							interval = 10
+							timeout  = 5
+							addr     = "wss://rt.local/socket"
+
+							expect "reply" {
+								contains = "pong"
+							}
+						}
+					}
+				}
+				`))
+
+				g.Assert(err.Error()).Equal("Reply expectation requires send")
+			})
+		})
+
+		g.Describe("SMTP check", func() {
+			g.It("Parses smtp check with starttls", func() {
+				c, err := ParseConfig("smtp.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "b" {
+						name = "b"
+						smtp {
+							interval = 60
+							timeout  = 10
+							addr     = "mx.ohdeer.dev:25"
+							starttls = true
+
+							expect "cert_days_left" {
+								min = 14
+							}
+						}
+					}
+				}
+				`))
+
+				g.Assert(err).IsNil()
+				smtp := c.Monitors[0].Services[0].SMTPChecks[0]
+				g.Assert(smtp.ServerName).Equal("mx.ohdeer.dev")
+				g.Assert(smtp.Hello).Equal("ohdeer.localhost")
+				g.Assert(*smtp.Expectations[0].Min).Equal(14)
+			})
+
+			g.It("Fails on cert expectation without starttls", func() {
+				_, err := ParseConfig("smtp.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "b" {
+						name = "b"
+						smtp {
+							interval = 60
+							timeout  = 10
+							addr     = "mx.ohdeer.dev:25"
+
+							expect "cert_days_left" {
+								min = 14
+							}
+						}
+					}
+				}
+				`))
+
+				g.Assert(err.Error()).Equal("Cert days left expectation requires starttls")
+			})
+		})
+
+		g.Describe("Banner check", func() {
+			g.It("Parses banner check", func() {
+				c, err := ParseConfig("banner.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "b" {
+						name = "b"
+						banner {
+							interval = 60
+							timeout  = 10
+							addr     = "imap.ohdeer.dev:143"
+
+							expect "reply" {
+								matches = "^\\* OK"
+							}
+						}
+					}
+				}
+				`))
+
+				g.Assert(err).IsNil()
+				banner := c.Monitors[0].Services[0].Banners[0]
+				g.Assert(banner.Addr).Equal("imap.ohdeer.dev:143")
+				g.Assert(banner.Expectations[0].Matches).Equal("^\\* OK")
+			})
+
+			g.It("Fails without expectations", func() {
+				_, err := ParseConfig("banner.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "b" {
+						name = "b"
+						banner {
+							interval = 60
+							timeout  = 10
+							addr     = "imap.ohdeer.dev:143"
+						}
+					}
+				}
+				`))
+
+				g.Assert(err.Error()).Equal("At least one expectation for banner check is required")
+			})
+		})
+
+		g.Describe("UDP check", func() {
+			g.It("Parses udp check", func() {
+				c, err := ParseConfig("udp.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "b" {
+						name = "b"
+						udp {
+							interval    = 60
+							timeout     = 5
+							addr        = "pool.ntp.org:123"
+							payload_hex = "e30004fa00010000"
+
+							expect "reply" {
+								contains_hex = "1c"
+							}
+						}
+					}
+				}
+				`))
+
+				g.Assert(err).IsNil()
+				udp := c.Monitors[0].Services[0].UDPChecks[0]
+				g.Assert(udp.payload).Equal([]byte{0xe3, 0x00, 0x04, 0xfa, 0x00, 0x01, 0x00, 0x00})
+				g.Assert(udp.Expectations[0].ContainsHex).Equal("1c")
+			})
+
+			g.It("Fails on invalid hex payload", func() {
+				_, err := ParseConfig("udp.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "b" {
+						name = "b"
+						udp {
+							interval    = 60
+							timeout     = 5
+							addr        = "pool.ntp.org:123"
+							payload_hex = "zz"
+						}
+					}
+				}
+				`))
+
+				g.Assert(err.Error()).Equal("Invalid payload_hex: encoding/hex: invalid byte: U+007A 'z'")
+			})
+		})
+
+		g.Describe("Scenario check", func() {
+			g.It("Parses scenario steps", func() {
+				c, err := ParseConfig("scenario.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "b" {
+						name = "b"
+						scenario {
+							interval = 60
+							timeout  = 10
+
+							step "login" {
+								addr   = "https://api.ohdeer.dev/login"
+								method = "post"
+
+								extract "token" {
+									json = "data.token"
+								}
+
+								expect "status" {
+									in = [200]
+								}
+							}
+
+							step "profile" {
+								addr    = "https://api.ohdeer.dev/me"
+								headers = {
+									Authorization = "Bearer {{token}}"
+								}
+
+								expect "status" {
+									in = [200]
+								}
+							}
+						}
+					}
+				}
+				`))
+
+				g.Assert(err).IsNil()
+				scenario := c.Monitors[0].Services[0].Scenarios[0]
+				g.Assert(len(scenario.Steps)).Equal(2)
+				g.Assert(scenario.Steps[0].Name).Equal("login")
+				g.Assert(scenario.Steps[0].http.Method).Equal("POST")
+				g.Assert(scenario.Steps[1].Headers["Authorization"]).Equal("Bearer {{token}}")
+			})
+
+			g.It("Fails on variable used before extraction", func() {
+				_, err := ParseConfig("scenario.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "b" {
+						name = "b"
+						scenario {
+							interval = 60
+							timeout  = 10
+
+							step "profile" {
+								addr = "https://api.ohdeer.dev/users/{{id}}"
+
+								expect "status" {
+									in = [200]
+								}
+							}
+						}
+					}
+				}
+				`))
+
+				g.Assert(err.Error()).Equal("Step profile: undefined variable id")
+			})
+		})
+
+		g.Describe("Size expectation", func() {
+			g.It("Parses size and body cap", func() {
+				c, err := ParseConfig("size.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "b" {
+						name = "b"
+						http {
+							interval       = 60
+							timeout        = 10
+							addr           = "https://www.ohdeer.dev/export.csv"
+							max_body_bytes = 1048576
+
+							expect "size" {
+								min = 1024
+							}
+						}
+					}
+				}
+				`))
+
+				g.Assert(err).IsNil()
+				http := c.Monitors[0].Services[0].HTTPChecks[0]
+				g.Assert(http.MaxBodyBytes).Equal(int64(1048576))
+				g.Assert(*http.Expectations[0].Min).Equal(1024)
+				g.Assert(http.Request().MaxBodyBytes).Equal(int64(1048576))
+			})
+
+			g.It("Fails without bounds", func() {
+				_, err := ParseConfig("size.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "b" {
+						name = "b"
+						http {
+							interval = 60
+							timeout  = 10
+							addr     = "https://www.ohdeer.dev/export.csv"
+
+							expect "size" {
+							}
+						}
+					}
+				}
+				`))
+
+				g.Assert(err.Error()).Equal("Size expectation requires min or max")
+			})
+		})
+
+		g.Describe("Body hash expectation", func() {
+			g.It("Fails on invalid pinned hash", func() {
+				_, err := ParseConfig("hash.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "b" {
+						name = "b"
+						http {
+							interval = 60
+							timeout  = 10
+							addr     = "https://www.ohdeer.dev/robots.txt"
+
+							expect "body_hash" {
+								equals = "abc"
+							}
+						}
+					}
+				}
+				`))
+
+				g.Assert(err.Error()).Equal("Body hash must be sha256 hex digest")
+			})
+
+			g.It("Fails on more than one body hash expectation", func() {
+				_, err := ParseConfig("hash.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "b" {
+						name = "b"
+						http {
+							interval = 60
+							timeout  = 10
+							addr     = "https://www.ohdeer.dev/robots.txt"
+
+							expect "body_hash" {
+							}
+
+							expect "body_hash" {
+								ignore = "\\d+"
+							}
+						}
+					}
+				}
+				`))
+
+				g.Assert(err.Error()).Equal("Only one body hash expectation can be set")
+			})
+		})
+
+		g.Describe("Retries", func() {
+			g.It("Parses retry options", func() {
+				c, err := ParseConfig("retries.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "b" {
+						name = "b"
+						http {
+							interval         = 60
+							timeout          = 10
+							addr             = "https://www.ohdeer.dev"
+							retries          = 2
+							retry_delay      = 1
+							confirm_failures = 3
+
+							expect "status" {
+								in = [200]
+							}
+						}
+						udp {
+							interval         = 10
+							timeout          = 2
+							addr             = "pool.ntp.org:123"
+							payload_hex      = "1b"
+							retries          = 3
+							confirm_failures = 2
+
+							expect "reply" {
+								contains_hex = "1c"
+							}
+						}
+					}
+				}
+				`))
+
+				g.Assert(err).IsNil()
+				http := c.Monitors[0].Services[0].HTTPChecks[0]
+				g.Assert(http.Retries).Equal(uint64(2))
+				g.Assert(http.RetryDelaySec).Equal(uint64(1))
+				g.Assert(http.ConfirmFailures).Equal(uint64(3))
+				udp := c.Monitors[0].Services[0].UDPChecks[0]
+				g.Assert(udp.retryPolicy()).Equal(retryPolicy{Retries: 3, ConfirmFailures: 2})
+			})
+
+			g.It("Fails when retries do not fit in the interval", func() {
+				_, err := ParseConfig("retries.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "b" {
+						name = "b"
+						tcp {
+							interval    = 30
+							timeout     = 10
+							addr        = "www.ohdeer.dev:443"
+							retries     = 2
+							retry_delay = 1
+						}
+					}
+				}
+				`))
+
+				g.Assert(err.Error()).Equal("Retries with timeout and delay (32s) must fit in the interval")
+			})
+
+			g.It("Fails on retry delay without retries", func() {
+				_, err := ParseConfig("retries.hcl", []byte(`
+				monitor "a" {
+					name = "a"
+					service "b" {
+						name = "b"
+						http {
+							interval    = 60
+							timeout     = 10
+							addr        = "https://www.ohdeer.dev"
+							retry_delay = 1
+
+							expect "status" {
+								in = [200]
+							}
+						}
+					}
+				}
+				`))
+
+				g.Assert(err.Error()).Equal("Retry delay requires retries")
+			})
+		})
+
+		g.Describe("Schedule", func() {
+			g.It("Assigns stable check IDs", func() {
+				c, err := ParseConfig("ids.hcl", []byte(`
+				monitor "aws" {
+					name = "a"
+					service "api" {
+						name = "b"
+						http {
+							interval = 60
+							timeout  = 10
+							jitter   = 5
+							addr     = "https://www.ohdeer.dev"
+
+							expect "status" {
+								in = [200]
+							}
+						}
+						tcp {
+							interval = 60
+							timeout  = 10
+							addr     = "www.ohdeer.dev:443"
+						}
+						tcp {
+							interval = 60
+							timeout  = 10
+							addr     = "www.ohdeer.dev:80"
+						}
+					}
+				}
+				`))
+
+				g.Assert(err).IsNil()
+				checks := c.Monitors[0].Services[0].Checks()
+				g.Assert(checks[0].ID()).Equal("aws/api/http/0d813d9f")
+				g.Assert(checks[0].Jitter()).Equal(5 * time.Second)
+				g.Assert(checks[1].ID()).Equal("aws/api/tcp/0a9b925b")
+				g.Assert(checks[2].ID()).Equal("aws/api/tcp/b967a132")
+			})
+
+			g.It("Keeps check IDs when other checks are added", func() {
+				parse := func(src string) []Check {
+					c, err := ParseConfig("ids.hcl", []byte(src))
+					if err != nil {
+						t.Fatal(err)
+					}
+					return c.Monitors[0].Services[0].Checks()
+				}
+				tcp := func(addr string) string {
+					return `tcp {
+						interval = 60
+						timeout  = 10
+						addr     = "` + addr + `"
+					}`
+				}
+				service := func(checks ...string) string {
+					return `monitor "aws" {
+						name = "a"
+						service "api" {
+							name = "b"
+							` + strings.Join(checks, "\n") + `
+						}
+					}`
+				}
+
+				before := parse(service(tcp("www.ohdeer.dev:443"), tcp("www.ohdeer.dev:80")))
+				after := parse(service(tcp("www.ohdeer.dev:22"), tcp("www.ohdeer.dev:443"), tcp("www.ohdeer.dev:80")))
+
+				g.Assert(after[1].ID()).Equal(before[0].ID())
+				g.Assert(after[2].ID()).Equal(before[1].ID())
+			})
+
+			g.It("Numbers checks with the same target", func() {
+				c, err := ParseConfig("ids.hcl", []byte(`
+				monitor "aws" {
+					name = "a"
+					service "api" {
+						name = "b"
+						tcp {
+							interval = 60
+							timeout  = 10
+							addr     = "www.ohdeer.dev:443"
+						}
+						tcp {
+							interval = 30
+							timeout  = 10
+							addr     = "www.ohdeer.dev:443"
+						}
+					}
+				}
+				`))
+
+				g.Assert(err).IsNil()
+				checks := c.Monitors[0].Services[0].Checks()
+				g.Assert(checks[1].ID()).Equal(checks[0].ID() + "-1")
+			})
+
+			g.It("Parses sub-second interval", func() {
+				c, err := ParseConfig("interval.hcl", []byte(`
+				monitor "aws" {
+					name = "a"
+					service "api" {
+						name = "b"
+						tcp {
+							interval = 0.5
+							timeout  = 1
+							addr     = "www.ohdeer.dev:443"
+						}
+					}
+				}
+				`))
+
+				g.Assert(err).IsNil()
+				g.Assert(c.Monitors[0].Services[0].Checks()[0].Interval()).Equal(500 * time.Millisecond)
+			})
+
+			g.It("Fails when interval is too short", func() {
+				_, err := ParseConfig("interval.hcl", []byte(`
+				monitor "aws" {
+					name = "a"
+					service "api" {
+						name = "b"
+						tcp {
+							interval = 0.05
+							timeout  = 1
+							addr     = "www.ohdeer.dev:443"
+						}
+					}
+				}
+				`))
+
+				g.Assert(err.Error()).Equal("Interval must be >= 0.1")
+			})
+
+			g.It("Fails when jitter is not lower than interval", func() {
+				_, err := ParseConfig("ids.hcl", []byte(`
+				monitor "aws" {
+					name = "a"
+					service "api" {
+						name = "b"
+						tcp {
+							interval = 60
+							timeout  = 10
+							jitter   = 60
+							addr     = "www.ohdeer.dev:443"
+						}
+					}
+				}
+				`))
+
+				g.Assert(err.Error()).Equal("Jitter must be < interval")
+			})
+		})
+
+		g.Describe("Concurrency", func() {
+			g.It("Parses runner and monitor limits", func() {
+				c, err := ParseConfig("runner.hcl", []byte(`
+				runner {
+					max_concurrency = 50
+				}
+
+				monitor "a" {
+					name            = "a"
+					max_concurrency = 5
+				}
+				`))
+
+				g.Assert(err).IsNil()
+				g.Assert(c.Runner.MaxConcurrency).Equal(50)
+				g.Assert(c.Monitors[0].MaxConcurrency).Equal(5)
+			})
+
+			g.It("Defaults to no limit", func() {
+				c, err := ParseConfig("runner.hcl", []byte(``))
+
+				g.Assert(err).IsNil()
+				g.Assert(c.Runner.MaxConcurrency).Equal(0)
+			})
+
+			g.It("Fails on negative limit", func() {
+				_, err := ParseConfig("runner.hcl", []byte(`
+				monitor "a" {
+					name            = "a"
+					max_concurrency = -1
+				}
+				`))
+
+				g.Assert(err.Error()).Equal("Max concurrency in monitor a must be >= 0")
+			})
+		})
+
 		g.Describe("Missing monitor ID", func() {
 			g.It("Fails", func() {
 				_, err := ParseConfig("http.hcl", []byte(`
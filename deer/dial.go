@@ -0,0 +1,79 @@
+package deer
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"time"
+)
+
+// dialTCP resolves the host and opens tcp connection.
+// Network can be one of tcp, tcp4 or tcp6.
+// Time spent on DNS lookup and connecting is written to trace.
+func dialTCP(ctx context.Context, network, addr string, timeout time.Duration, trace *Trace) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	trace.DNSLookup = time.Since(start)
+	if err != nil {
+		return nil, err
+	}
+
+	var ip *net.IPAddr
+	for i := range ips {
+		is4 := ips[i].IP.To4() != nil
+		if network == "tcp" || (network == "tcp4" && is4) || (network == "tcp6" && !is4) {
+			ip = &ips[i]
+			break
+		}
+	}
+	if ip == nil {
+		return nil, fmt.Errorf("No %s address found for %s", network, host)
+	}
+
+	start = time.Now()
+	conn, err := (&net.Dialer{}).DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+	trace.TCPConnection = time.Since(start)
+
+	return conn, err
+}
+
+// closeOnDone closes connection when ctx is cancelled, so that blocked reads and writes return early.
+// Returned function stops watching the context.
+func closeOnDone(ctx context.Context, conn net.Conn) func() {
+	stop := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-stop:
+		}
+	}()
+
+	return func() {
+		close(stop)
+	}
+}
+
+// readLine reads a single line (e.g. greeting banner) from connection.
+// When connection does not end the line before deadline, partial data is returned.
+func readLine(conn net.Conn, deadline time.Time) (string, error) {
+	if err := conn.SetReadDeadline(deadline); err != nil {
+		return "", err
+	}
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil && len(line) > 0 {
+		err = nil
+	}
+
+	return line, err
+}
@@ -0,0 +1,242 @@
+package deer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+)
+
+// DNSCheck defines dns resolution type check.
+type DNSCheck struct {
+	ref
+
+	// body
+	IntervalSec     float64  `hcl:"interval"`
+	TimeoutSec      uint64   `hcl:"timeout"`
+	JitterSec       uint64   `hcl:"jitter,optional"`
+	Retries         uint64   `hcl:"retries,optional"`
+	RetryDelaySec   uint64   `hcl:"retry_delay,optional"`
+	ConfirmFailures uint64   `hcl:"confirm_failures,optional"`
+	Name            string   `hcl:"name"`
+	RecordType      string   `hcl:"type,optional"`
+	Resolver        string   `hcl:"resolver,optional"`
+	Expectations    []Expect `hcl:"expect,block"`
+}
+
+// DNSResponse contains the result of the dns check.
+type DNSResponse struct {
+	Err     error
+	Answers []string
+	Trace   Trace
+}
+
+// DNSDetails contains dns lookup answers.
+type DNSDetails struct {
+	Type    string   `json:"type"`
+	Answers []string `json:"answers"`
+}
+
+var dnsRecordTypes = map[string]bool{
+	"A":     true,
+	"AAAA":  true,
+	"CNAME": true,
+	"MX":    true,
+	"TXT":   true,
+	"SRV":   true,
+}
+
+// Validate ensures correct values are set for dns check.
+func (d *DNSCheck) Validate() error {
+	if err := validateSchedule(d.IntervalSec, d.TimeoutSec, d.JitterSec, d.retryPolicy()); err != nil {
+		return err
+	}
+
+	if d.RecordType == "" {
+		d.RecordType = "A"
+	}
+
+	switch {
+	case len(d.Name) == 0:
+		return fmt.Errorf("Name cannot be empty")
+
+	case !dnsRecordTypes[d.RecordType]:
+		return fmt.Errorf("Invalid record type %s", d.RecordType)
+	}
+
+	if d.Resolver != "" {
+		if _, _, err := net.SplitHostPort(d.Resolver); err != nil {
+			return fmt.Errorf("Resolver must be in host:port format")
+		}
+	}
+
+	for _, expect := range d.Expectations {
+		switch expect.Subject {
+		case "record":
+			if err := expect.validateMatcher("Record"); err != nil {
+				return err
+			}
+		case "record_count":
+			if expect.Min == nil && expect.Max == nil {
+				return fmt.Errorf("Record count expectation requires min or max")
+			}
+		default:
+			return fmt.Errorf("Invalid expectation subject")
+		}
+	}
+
+	return nil
+}
+
+// Interval returns how often check should be run.
+func (d *DNSCheck) Interval() time.Duration {
+	return seconds(d.IntervalSec)
+}
+
+// Jitter returns maximum random delay added to each run.
+func (d *DNSCheck) Jitter() time.Duration {
+	return time.Duration(d.JitterSec) * time.Second
+}
+
+// target returns record type and name, from which check ID is derived.
+func (d *DNSCheck) target() string {
+	return d.RecordType + " " + d.Name
+}
+
+// retryPolicy returns how failed probes are retried.
+func (d *DNSCheck) retryPolicy() retryPolicy {
+	return retryPolicy{d.Retries, d.RetryDelaySec, d.ConfirmFailures}
+}
+
+// RunFn returns task function to run check.
+func (d *DNSCheck) RunFn(s Store) func(context.Context) {
+	store := s
+
+	return func(ctx context.Context) {
+		d.ref.run(ctx, store, d.retryPolicy(), "", func() CheckResult {
+			now := time.Now()
+			resp := d.Lookup(ctx, time.Duration(d.TimeoutSec)*time.Second)
+
+			result := d.ref.result(now)
+			result.Trace = &resp.Trace
+			result.verdict(d.Verify(resp))
+			result.Details = &Details{DNS: &DNSDetails{Type: d.RecordType, Answers: resp.Answers}}
+
+			return result
+		})
+	}
+}
+
+// Lookup queries the resolver for the configured name and record type.
+// Answers are formatted as strings, e.g. "10 mx.example.com." for MX records.
+func (d *DNSCheck) Lookup(ctx context.Context, timeout time.Duration) *DNSResponse {
+	var resp DNSResponse
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	resolver := net.DefaultResolver
+	if d.Resolver != "" {
+		addr := d.Resolver
+		resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, network, addr)
+			},
+		}
+	}
+
+	start := time.Now()
+	resp.Answers, resp.Err = lookupRecords(ctx, resolver, d.RecordType, d.Name)
+	resp.Trace.DNSLookup = time.Since(start)
+	resp.Trace.Total = resp.Trace.DNSLookup
+
+	return &resp
+}
+
+// Check verifies if check is valid or not.
+func (d *DNSCheck) Check(resp *DNSResponse) bool {
+	return d.Verify(resp) == nil
+}
+
+// Verify returns the reason why check is not valid or nil when it passes.
+func (d *DNSCheck) Verify(resp *DNSResponse) error {
+	if resp.Err != nil {
+		return resp.Err
+	}
+
+	for _, expect := range d.Expectations {
+		switch expect.Subject {
+		case "record":
+			found := false
+			for _, answer := range resp.Answers {
+				if expect.matchString(answer) {
+					found = true
+				}
+			}
+
+			if !found {
+				return fmt.Errorf("No %s record matches expectation in %v", d.RecordType, resp.Answers)
+			}
+
+		case "record_count":
+			if err := expect.verifyRange("Record count", len(resp.Answers)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func lookupRecords(ctx context.Context, r *net.Resolver, recordType, name string) ([]string, error) {
+	answers := make([]string, 0)
+
+	switch recordType {
+	case "A", "AAAA":
+		network := "ip4"
+		if recordType == "AAAA" {
+			network = "ip6"
+		}
+		ips, err := r.LookupIP(ctx, network, name)
+		if err != nil {
+			return answers, err
+		}
+		for _, ip := range ips {
+			answers = append(answers, ip.String())
+		}
+
+	case "CNAME":
+		cname, err := r.LookupCNAME(ctx, name)
+		if err != nil {
+			return answers, err
+		}
+		answers = append(answers, cname)
+
+	case "MX":
+		mxs, err := r.LookupMX(ctx, name)
+		if err != nil {
+			return answers, err
+		}
+		for _, mx := range mxs {
+			answers = append(answers, fmt.Sprintf("%d %s", mx.Pref, mx.Host))
+		}
+
+	case "TXT":
+		txts, err := r.LookupTXT(ctx, name)
+		if err != nil {
+			return answers, err
+		}
+		answers = append(answers, txts...)
+
+	case "SRV":
+		_, srvs, err := r.LookupSRV(ctx, "", "", name)
+		if err != nil {
+			return answers, err
+		}
+		for _, srv := range srvs {
+			answers = append(answers, fmt.Sprintf("%d %d %d %s", srv.Priority, srv.Weight, srv.Port, srv.Target))
+		}
+	}
+
+	return answers, nil
+}
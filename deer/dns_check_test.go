@@ -0,0 +1,140 @@
+package deer
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/franela/goblin"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// dnsStandIn is a local dns server answering questions with predefined resources.
+type dnsStandIn struct {
+	conn    net.PacketConn
+	records map[dnsmessage.Type][]dnsmessage.ResourceBody
+}
+
+func newDNSStandIn(records map[dnsmessage.Type][]dnsmessage.ResourceBody) (*dnsStandIn, error) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &dnsStandIn{conn: conn, records: records}
+	go s.serve()
+	return s, nil
+}
+
+func (s *dnsStandIn) Addr() string {
+	return s.conn.LocalAddr().String()
+}
+
+func (s *dnsStandIn) Close() {
+	s.conn.Close()
+}
+
+func (s *dnsStandIn) serve() {
+	buf := make([]byte, 512)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+
+		var msg dnsmessage.Message
+		if err := msg.Unpack(buf[:n]); err != nil || len(msg.Questions) == 0 {
+			continue
+		}
+		q := msg.Questions[0]
+
+		msg.Header.Response = true
+		msg.Header.Authoritative = true
+		msg.Answers = nil
+		for _, body := range s.records[q.Type] {
+			msg.Answers = append(msg.Answers, dnsmessage.Resource{
+				Header: dnsmessage.ResourceHeader{Name: q.Name, Type: q.Type, Class: q.Class, TTL: 60},
+				Body:   body,
+			})
+		}
+
+		out, err := msg.Pack()
+		if err != nil {
+			continue
+		}
+		s.conn.WriteTo(out, addr)
+	}
+}
+
+func TestDNSCheck(t *testing.T) {
+	g := goblin.Goblin(t)
+	g.Describe("DNSCheck", func() {
+		var srv *dnsStandIn
+
+		g.Before(func() {
+			var err error
+			srv, err = newDNSStandIn(map[dnsmessage.Type][]dnsmessage.ResourceBody{
+				dnsmessage.TypeA: {
+					&dnsmessage.AResource{A: [4]byte{10, 0, 0, 1}},
+					&dnsmessage.AResource{A: [4]byte{10, 0, 0, 2}},
+				},
+				dnsmessage.TypeMX: {
+					&dnsmessage.MXResource{Pref: 10, MX: dnsmessage.MustNewName("mx.ohdeer.test.")},
+				},
+				dnsmessage.TypeTXT: {
+					&dnsmessage.TXTResource{TXT: []string{"v=spf1 -all"}},
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+
+		g.After(func() {
+			srv.Close()
+		})
+
+		g.It("Resolves A records", func() {
+			min := 2
+			check := &DNSCheck{
+				Name:       "ohdeer.test.",
+				RecordType: "A",
+				Resolver:   srv.Addr(),
+				Expectations: []Expect{
+					{Subject: "record", Equals: "10.0.0.2"},
+					{Subject: "record_count", Min: &min},
+				},
+			}
+			resp := check.Lookup(context.Background(), time.Second)
+
+			g.Assert(resp.Err).IsNil()
+			g.Assert(resp.Answers).Equal([]string{"10.0.0.1", "10.0.0.2"})
+			g.Assert(check.Check(resp)).IsTrue()
+		})
+
+		g.It("Resolves MX and TXT records", func() {
+			check := &DNSCheck{
+				Name:         "ohdeer.test.",
+				RecordType:   "MX",
+				Resolver:     srv.Addr(),
+				Expectations: []Expect{{Subject: "record", Equals: "10 mx.ohdeer.test."}},
+			}
+			g.Assert(check.Check(check.Lookup(context.Background(), time.Second))).IsTrue()
+
+			check.RecordType = "TXT"
+			check.Expectations = []Expect{{Subject: "record", Matches: "^v=spf1"}}
+			g.Assert(check.Check(check.Lookup(context.Background(), time.Second))).IsTrue()
+		})
+
+		g.It("Fails when record is missing", func() {
+			check := &DNSCheck{
+				Name:         "ohdeer.test.",
+				RecordType:   "A",
+				Resolver:     srv.Addr(),
+				Expectations: []Expect{{Subject: "record", Equals: "10.0.0.3"}},
+			}
+
+			g.Assert(check.Check(check.Lookup(context.Background(), time.Second))).IsFalse()
+		})
+	})
+}
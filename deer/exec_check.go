@@ -0,0 +1,249 @@
+package deer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// maxExecOutput limits how much of command output is kept.
+const maxExecOutput = 4096
+
+// ExecCheck defines command type check.
+// Command succeeds when it exits with 0 unless exit_code expectation says otherwise.
+type ExecCheck struct {
+	ref
+
+	// body
+	IntervalSec     float64           `hcl:"interval"`
+	TimeoutSec      uint64            `hcl:"timeout"`
+	JitterSec       uint64            `hcl:"jitter,optional"`
+	Retries         uint64            `hcl:"retries,optional"`
+	RetryDelaySec   uint64            `hcl:"retry_delay,optional"`
+	ConfirmFailures uint64            `hcl:"confirm_failures,optional"`
+	Command         []string          `hcl:"command"`
+	Env             map[string]string `hcl:"env,optional"`
+	Dir             string            `hcl:"dir,optional"`
+	Expectations    []Expect          `hcl:"expect,block"`
+}
+
+// ExecResponse contains the result of the command.
+type ExecResponse struct {
+	Err      error
+	ExitCode int
+	Stdout   string
+	Stderr   string
+	Trace    Trace
+}
+
+// ExecDetails contains command result details.
+type ExecDetails struct {
+	ExitCode int    `json:"exit_code"`
+	Stdout   string `json:"stdout,omitempty"`
+	Stderr   string `json:"stderr,omitempty"`
+}
+
+// Validate ensures correct values are set for exec check.
+func (e *ExecCheck) Validate() error {
+	if err := validateSchedule(e.IntervalSec, e.TimeoutSec, e.JitterSec, e.retryPolicy()); err != nil {
+		return err
+	}
+
+	if len(e.Command) == 0 || len(e.Command[0]) == 0 {
+		return fmt.Errorf("Command cannot be empty")
+	}
+
+	for _, expect := range e.Expectations {
+		switch expect.Subject {
+		case "exit_code":
+			if len(expect.Inclusion) == 0 {
+				return fmt.Errorf("Exit code expectation requires at least one value in")
+			}
+		case "stdout", "stderr":
+			if err := expect.validateMatcher("Output"); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("Invalid expectation subject")
+		}
+	}
+
+	return nil
+}
+
+// Interval returns how often check should be run.
+func (e *ExecCheck) Interval() time.Duration {
+	return seconds(e.IntervalSec)
+}
+
+// Jitter returns maximum random delay added to each run.
+func (e *ExecCheck) Jitter() time.Duration {
+	return time.Duration(e.JitterSec) * time.Second
+}
+
+// target returns command, from which check ID is derived.
+func (e *ExecCheck) target() string {
+	return strings.Join(e.Command, " ")
+}
+
+// retryPolicy returns how failed probes are retried.
+func (e *ExecCheck) retryPolicy() retryPolicy {
+	return retryPolicy{e.Retries, e.RetryDelaySec, e.ConfirmFailures}
+}
+
+// RunFn returns task function to run check.
+func (e *ExecCheck) RunFn(s Store) func(context.Context) {
+	store := s
+
+	return func(ctx context.Context) {
+		e.ref.run(ctx, store, e.retryPolicy(), "", func() CheckResult {
+			now := time.Now()
+			resp := e.Run(ctx, time.Duration(e.TimeoutSec)*time.Second)
+
+			result := e.ref.result(now)
+			result.Trace = &resp.Trace
+			result.verdict(e.Verify(resp))
+			result.Details = &Details{Exec: &ExecDetails{
+				ExitCode: resp.ExitCode,
+				Stdout:   resp.Stdout,
+				Stderr:   resp.Stderr,
+			}}
+
+			return result
+		})
+	}
+}
+
+// Run executes the command and captures truncated output.
+// Command and its children are killed when it does not finish before timeout.
+func (e *ExecCheck) Run(ctx context.Context, timeout time.Duration) *ExecResponse {
+	var resp ExecResponse
+	stdout := cappedWriter{limit: maxExecOutput}
+	stderr := cappedWriter{limit: maxExecOutput}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.Command(e.Command[0], e.Command[1:]...)
+	cmd.Dir = e.Dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	setProcessGroup(cmd)
+	if len(e.Env) > 0 {
+		cmd.Env = os.Environ()
+		for k, v := range e.Env {
+			cmd.Env = append(cmd.Env, k+"="+v)
+		}
+	}
+
+	start := time.Now()
+	err := runCmd(ctx, cmd)
+	resp.Trace.Total = time.Since(start)
+
+	resp.Stdout = stdout.String()
+	resp.Stderr = stderr.String()
+
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		resp.Err = fmt.Errorf("Command timed out after %s", timeout)
+		resp.ExitCode = -1
+
+	case err != nil:
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			resp.ExitCode = exitErr.ExitCode()
+		} else {
+			resp.Err = err
+			resp.ExitCode = -1
+		}
+	}
+
+	return &resp
+}
+
+// runCmd runs command and kills its whole process group when ctx is done,
+// so that children still holding output pipes do not keep it running past the deadline.
+func runCmd(ctx context.Context, cmd *exec.Cmd) error {
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		killProcessGroup(cmd)
+		return <-done
+	}
+}
+
+// cappedWriter keeps first limit bytes written and discards the rest.
+type cappedWriter struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (w *cappedWriter) Write(p []byte) (int, error) {
+	if free := w.limit - w.buf.Len(); len(p) > free {
+		w.buf.Write(p[:free])
+		w.truncated = true
+	} else {
+		w.buf.Write(p)
+	}
+	return len(p), nil
+}
+
+// String returns kept output, ellipsis marks discarded part.
+func (w *cappedWriter) String() string {
+	if w.truncated {
+		return w.buf.String() + "..."
+	}
+	return w.buf.String()
+}
+
+// Check verifies if check is valid or not.
+func (e *ExecCheck) Check(resp *ExecResponse) bool {
+	return e.Verify(resp) == nil
+}
+
+// Verify returns the reason why check is not valid or nil when it passes.
+func (e *ExecCheck) Verify(resp *ExecResponse) error {
+	if resp.Err != nil {
+		return resp.Err
+	}
+
+	exitCodes := []int{0}
+
+	for _, expect := range e.Expectations {
+		switch expect.Subject {
+		case "exit_code":
+			exitCodes = expect.Inclusion
+
+		case "stdout":
+			if err := expect.verifyString("Stdout", resp.Stdout); err != nil {
+				return err
+			}
+
+		case "stderr":
+			if err := expect.verifyString("Stderr", resp.Stderr); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, code := range exitCodes {
+		if code == resp.ExitCode {
+			return nil
+		}
+	}
+	return fmt.Errorf("Exit code %d is not in %v", resp.ExitCode, exitCodes)
+}
@@ -0,0 +1,68 @@
+package deer
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/franela/goblin"
+)
+
+func TestExecCheck(t *testing.T) {
+	g := goblin.Goblin(t)
+	g.Describe("ExecCheck", func() {
+		g.It("Passes on exit code 0 and captures output", func() {
+			check := &ExecCheck{
+				Command:      []string{"sh", "-c", "echo queue=$QUEUE; echo warn >&2"},
+				Env:          map[string]string{"QUEUE": "12"},
+				Expectations: []Expect{{Subject: "stdout", Matches: `queue=\d+`}},
+			}
+			resp := check.Run(context.Background(), time.Second)
+
+			g.Assert(check.Verify(resp)).IsNil()
+			g.Assert(resp.Stdout).Equal("queue=12\n")
+			g.Assert(resp.Stderr).Equal("warn\n")
+		})
+
+		g.It("Fails on non-zero exit code", func() {
+			check := &ExecCheck{Command: []string{"sh", "-c", "exit 2"}}
+			resp := check.Run(context.Background(), time.Second)
+
+			g.Assert(resp.ExitCode).Equal(2)
+			g.Assert(check.Verify(resp).Error()).Equal("Exit code 2 is not in [0]")
+		})
+
+		g.It("Matches expected exit code", func() {
+			check := &ExecCheck{
+				Command:      []string{"sh", "-c", "exit 2"},
+				Expectations: []Expect{{Subject: "exit_code", Inclusion: []int{1, 2}}},
+			}
+
+			g.Assert(check.Check(check.Run(context.Background(), time.Second))).IsTrue()
+		})
+
+		g.It("Kills command on timeout", func() {
+			check := &ExecCheck{Command: []string{"sleep", "5"}}
+			resp := check.Run(context.Background(), 100*time.Millisecond)
+
+			g.Assert(check.Verify(resp).Error()).Equal("Command timed out after 100ms")
+		})
+
+		g.It("Kills children holding output on timeout", func() {
+			check := &ExecCheck{Command: []string{"sh", "-c", "sleep 5; echo done"}}
+			resp := check.Run(context.Background(), 100*time.Millisecond)
+
+			g.Assert(check.Verify(resp).Error()).Equal("Command timed out after 100ms")
+			g.Assert(resp.Trace.Total < time.Second).IsTrue()
+		})
+
+		g.It("Truncates output", func() {
+			check := &ExecCheck{Command: []string{"sh", "-c", "head -c 10000 /dev/zero | tr '\\0' a"}}
+			resp := check.Run(context.Background(), time.Second)
+
+			g.Assert(len(resp.Stdout)).Equal(maxExecOutput + 3)
+			g.Assert(strings.HasSuffix(resp.Stdout, "...")).IsTrue()
+		})
+	})
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+// +build !windows
+
+package deer
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts command in its own process group.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killProcessGroup kills command together with all its children.
+func killProcessGroup(cmd *exec.Cmd) {
+	syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
@@ -0,0 +1,13 @@
+package deer
+
+import (
+	"os/exec"
+)
+
+// setProcessGroup is no-op, process groups are not supported.
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// killProcessGroup kills command only, its children are left running.
+func killProcessGroup(cmd *exec.Cmd) {
+	cmd.Process.Kill()
+}
@@ -1,9 +1,133 @@
 package deer
 
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
 // Expect defines service assertion.
 type Expect struct {
 	// label
 	Subject string `hcl:"subject,label"`
 	// body
-	Inclusion []int `hcl:"in"`
+	Inclusion   []int  `hcl:"in,optional"`
+	Name        string `hcl:"name,optional"`
+	Path        string `hcl:"path,optional"`
+	Equals      string `hcl:"equals,optional"`
+	Contains    string `hcl:"contains,optional"`
+	NotContains string `hcl:"not_contains,optional"`
+	Matches     string `hcl:"matches,optional"`
+	ContainsHex string `hcl:"contains_hex,optional"`
+	Ignore      string `hcl:"ignore,optional"`
+	Present     bool   `hcl:"present,optional"`
+	Absent      bool   `hcl:"absent,optional"`
+	Min         *int   `hcl:"min,optional"`
+	Max         *int   `hcl:"max,optional"`
+	Degrade     bool   `hcl:"degrade,optional"`
+
+	// latency thresholds
+	DNSLookupMs        *int `hcl:"dns_lookup_ms,optional"`
+	TCPConnectionMs    *int `hcl:"tcp_connection_ms,optional"`
+	TLSHandshakeMs     *int `hcl:"tls_handshake_ms,optional"`
+	ServerProcessingMs *int `hcl:"server_processing_ms,optional"`
+	ContentTransferMs  *int `hcl:"content_transfer_ms,optional"`
+	TotalMs            *int `hcl:"total_ms,optional"`
+}
+
+// verifyRange returns mismatch reason when value is outside min and max bounds (if set).
+func (e *Expect) verifyRange(what string, v int) error {
+	if e.Min != nil && v < *e.Min {
+		return fmt.Errorf("%s %d is lower than %d", what, v, *e.Min)
+	}
+	if e.Max != nil && v > *e.Max {
+		return fmt.Errorf("%s %d is greater than %d", what, v, *e.Max)
+	}
+	return nil
+}
+
+// matchString returns true when value satisfies all of equals, contains, not_contains and matches (if set).
+func (e *Expect) matchString(v string) bool {
+	return e.verifyString("Value", v) == nil
+}
+
+// verifyString returns mismatch reason when value does not satisfy
+// any of equals, contains, not_contains and matches (if set).
+func (e *Expect) verifyString(what, v string) error {
+	switch {
+	case e.Equals != "" && v != e.Equals:
+		return fmt.Errorf("%s %q does not equal %q", what, truncate(v, 64), e.Equals)
+
+	case e.Contains != "" && !strings.Contains(v, e.Contains):
+		return fmt.Errorf("%s %q does not contain %q", what, truncate(v, 64), e.Contains)
+
+	case e.NotContains != "" && strings.Contains(v, e.NotContains):
+		return fmt.Errorf("%s %q contains %q", what, truncate(v, 64), e.NotContains)
+
+	case e.Matches != "" && !regexp.MustCompile(e.Matches).MatchString(v):
+		return fmt.Errorf("%s %q does not match %q", what, truncate(v, 64), e.Matches)
+	}
+	return nil
+}
+
+// validateMatcher ensures at least one string matcher is set and regexp is valid.
+func (e *Expect) validateMatcher(name string) error {
+	if e.Equals == "" && e.Contains == "" && e.NotContains == "" && e.Matches == "" {
+		return fmt.Errorf("%s expectation requires equals, contains, not_contains or matches", name)
+	}
+	if _, err := regexp.Compile(e.Matches); err != nil {
+		return fmt.Errorf("Invalid %s regexp: %v", strings.ToLower(name), err)
+	}
+	return nil
+}
+
+// latencyLimits returns trace phase limits defined by expectation.
+func (e *Expect) latencyLimits(trace *Trace) []latencyLimit {
+	all := []latencyLimit{
+		{"DNS lookup", e.DNSLookupMs, trace.DNSLookup},
+		{"TCP connection", e.TCPConnectionMs, trace.TCPConnection},
+		{"TLS handshake", e.TLSHandshakeMs, trace.TLSHandshake},
+		{"Server processing", e.ServerProcessingMs, trace.ServerProcessing},
+		{"Content transfer", e.ContentTransferMs, trace.ContentTransfer},
+		{"Total", e.TotalMs, trace.Total},
+	}
+
+	limits := make([]latencyLimit, 0, len(all))
+	for _, l := range all {
+		if l.maxMs != nil {
+			limits = append(limits, l)
+		}
+	}
+	return limits
+}
+
+// verifyLatency returns the reason when any of trace phases exceeds its limit.
+// When degrade is set the reason is wrapped in DegradedError.
+func (e *Expect) verifyLatency(trace *Trace) error {
+	for _, l := range e.latencyLimits(trace) {
+		max := time.Duration(*l.maxMs) * time.Millisecond
+		if l.took > max {
+			err := fmt.Errorf("%s took %dms (max %dms)", l.phase, l.took/time.Millisecond, *l.maxMs)
+			if e.Degrade {
+				return &DegradedError{Reason: err}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+type latencyLimit struct {
+	phase string
+	maxMs *int
+	took  time.Duration
+}
+
+// truncate shortens string to at most n bytes.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
 }
@@ -0,0 +1,173 @@
+package deer
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// GRPCCheck defines grpc health checking protocol type check.
+type GRPCCheck struct {
+	ref
+
+	// body
+	IntervalSec     float64  `hcl:"interval"`
+	TimeoutSec      uint64   `hcl:"timeout"`
+	JitterSec       uint64   `hcl:"jitter,optional"`
+	Retries         uint64   `hcl:"retries,optional"`
+	RetryDelaySec   uint64   `hcl:"retry_delay,optional"`
+	ConfirmFailures uint64   `hcl:"confirm_failures,optional"`
+	Addr            string   `hcl:"addr"`
+	Service         string   `hcl:"service,optional"`
+	TLS             bool     `hcl:"tls,optional"`
+	ServerName      string   `hcl:"server_name,optional"`
+	Expectations    []Expect `hcl:"expect,block"`
+}
+
+// GRPCResponse contains the result of the health check call.
+type GRPCResponse struct {
+	Err    error
+	Status string
+	Trace  Trace
+}
+
+// GRPCDetails contains reported serving status.
+type GRPCDetails struct {
+	Status string `json:"status"`
+}
+
+// Validate ensures correct values are set for grpc check.
+func (c *GRPCCheck) Validate() error {
+	if err := validateSchedule(c.IntervalSec, c.TimeoutSec, c.JitterSec, c.retryPolicy()); err != nil {
+		return err
+	}
+
+	if len(c.Addr) == 0 {
+		return fmt.Errorf("Addr cannot be empty")
+	}
+
+	for _, expect := range c.Expectations {
+		switch expect.Subject {
+		case "status":
+			if err := expect.validateMatcher("Status"); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("Invalid expectation subject")
+		}
+	}
+
+	return nil
+}
+
+// Interval returns how often check should be run.
+func (c *GRPCCheck) Interval() time.Duration {
+	return seconds(c.IntervalSec)
+}
+
+// Jitter returns maximum random delay added to each run.
+func (c *GRPCCheck) Jitter() time.Duration {
+	return time.Duration(c.JitterSec) * time.Second
+}
+
+// target returns addr and service name, from which check ID is derived.
+func (c *GRPCCheck) target() string {
+	return c.Addr + "/" + c.Service
+}
+
+// retryPolicy returns how failed probes are retried.
+func (c *GRPCCheck) retryPolicy() retryPolicy {
+	return retryPolicy{c.Retries, c.RetryDelaySec, c.ConfirmFailures}
+}
+
+// RunFn returns task function to run check.
+func (c *GRPCCheck) RunFn(s Store) func(context.Context) {
+	store := s
+
+	return func(ctx context.Context) {
+		c.ref.run(ctx, store, c.retryPolicy(), "", func() CheckResult {
+			now := time.Now()
+			resp := c.Call(ctx, time.Duration(c.TimeoutSec)*time.Second)
+
+			result := c.ref.result(now)
+			result.Trace = &resp.Trace
+			result.verdict(c.Verify(resp))
+			if resp.Err == nil {
+				result.Details = &Details{GRPC: &GRPCDetails{Status: resp.Status}}
+			}
+
+			return result
+		})
+	}
+}
+
+// Call connects to the server and calls grpc.health.v1.Health/Check.
+// Connection time (including tls) is stored as TCP connection and call time as server processing.
+func (c *GRPCCheck) Call(ctx context.Context, timeout time.Duration) *GRPCResponse {
+	var resp GRPCResponse
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	creds := grpc.WithInsecure()
+	if c.TLS {
+		creds = grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{ServerName: c.ServerName}))
+	}
+
+	start := time.Now()
+	defer func() {
+		resp.Trace.Total = time.Since(start)
+	}()
+
+	conn, err := grpc.DialContext(ctx, c.Addr, creds, grpc.WithBlock(), grpc.WithUserAgent(DefaultUserAgent))
+	resp.Trace.TCPConnection = time.Since(start)
+	if err != nil {
+		resp.Err = err
+		return &resp
+	}
+	defer conn.Close()
+
+	started := time.Now()
+	out, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: c.Service})
+	resp.Trace.ServerProcessing = time.Since(started)
+	if err != nil {
+		resp.Err = err
+		return &resp
+	}
+	resp.Status = out.GetStatus().String()
+
+	return &resp
+}
+
+// Check verifies if check is valid or not.
+func (c *GRPCCheck) Check(resp *GRPCResponse) bool {
+	return c.Verify(resp) == nil
+}
+
+// Verify returns the reason why check is not valid or nil when it passes.
+// Without status expectation server is expected to be SERVING.
+func (c *GRPCCheck) Verify(resp *GRPCResponse) error {
+	if resp.Err != nil {
+		return resp.Err
+	}
+
+	expectations := c.Expectations
+	if len(expectations) == 0 {
+		expectations = []Expect{{Subject: "status", Equals: healthpb.HealthCheckResponse_SERVING.String()}}
+	}
+
+	for _, expect := range expectations {
+		switch expect.Subject {
+		case "status":
+			if err := expect.verifyString("Status", resp.Status); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
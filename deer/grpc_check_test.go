@@ -0,0 +1,63 @@
+package deer
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/franela/goblin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestGRPCCheck(t *testing.T) {
+	g := goblin.Goblin(t)
+	g.Describe("GRPCCheck", func() {
+		var (
+			ln  net.Listener
+			srv *grpc.Server
+		)
+
+		g.Before(func() {
+			var err error
+			ln, err = net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			hs := health.NewServer()
+			hs.SetServingStatus("billing", healthpb.HealthCheckResponse_SERVING)
+			hs.SetServingStatus("search", healthpb.HealthCheckResponse_NOT_SERVING)
+
+			srv = grpc.NewServer()
+			healthpb.RegisterHealthServer(srv, hs)
+			go srv.Serve(ln)
+		})
+
+		g.After(func() {
+			srv.Stop()
+		})
+
+		g.It("Passes when service is serving", func() {
+			check := &GRPCCheck{Addr: ln.Addr().String(), Service: "billing"}
+			resp := check.Call(context.Background(), time.Second)
+
+			g.Assert(check.Verify(resp)).IsNil()
+			g.Assert(resp.Status).Equal("SERVING")
+		})
+
+		g.It("Fails when service is not serving", func() {
+			check := &GRPCCheck{Addr: ln.Addr().String(), Service: "search"}
+
+			g.Assert(check.Verify(check.Call(context.Background(), time.Second)).Error()).Equal(`Status "NOT_SERVING" does not equal "SERVING"`)
+		})
+
+		g.It("Fails when service is unknown", func() {
+			check := &GRPCCheck{Addr: ln.Addr().String(), Service: "unknown"}
+
+			g.Assert(check.Check(check.Call(context.Background(), time.Second))).IsFalse()
+		})
+	})
+}
@@ -0,0 +1,123 @@
+package deer
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// HeartbeatCheck defines push based check.
+// Instead of probing, monitored job pings the heartbeat endpoint
+// and check fails whenever the ping is overdue.
+type HeartbeatCheck struct {
+	ref
+
+	// body
+	PeriodSec   uint64  `hcl:"period"`
+	GraceSec    uint64  `hcl:"grace,optional"`
+	IntervalSec float64 `hcl:"interval,optional"`
+	TokenEnv    string  `hcl:"token_env,optional"`
+	TokenFile   string  `hcl:"token_file,optional"`
+
+	token    string
+	mu       sync.Mutex
+	lastPing time.Time
+}
+
+// Validate ensures correct values are set for heartbeat check.
+func (h *HeartbeatCheck) Validate() error {
+	if h.PeriodSec <= 0 {
+		return fmt.Errorf("Period must be > 0")
+	}
+	if h.IntervalSec == 0 {
+		h.IntervalSec = float64(h.PeriodSec)
+	}
+	if h.IntervalSec < minIntervalSec {
+		return fmt.Errorf("Interval must be >= %g", minIntervalSec)
+	}
+
+	token, err := readSecret(h.TokenEnv, h.TokenFile)
+	if err != nil {
+		return fmt.Errorf("Heartbeat token: %v", err)
+	}
+	if len(token) == 0 {
+		return fmt.Errorf("Heartbeat token cannot be empty")
+	}
+	h.token = token
+
+	return nil
+}
+
+// Interval returns how often overdue pings are checked.
+func (h *HeartbeatCheck) Interval() time.Duration {
+	return seconds(h.IntervalSec)
+}
+
+// Jitter returns no delay, overdue pings are checked on time.
+func (h *HeartbeatCheck) Jitter() time.Duration {
+	return 0
+}
+
+// target returns token source, from which check ID is derived.
+func (h *HeartbeatCheck) target() string {
+	return h.TokenEnv + h.TokenFile
+}
+
+// RunFn returns task function that records failure when ping is overdue.
+func (h *HeartbeatCheck) RunFn(s Store) func(context.Context) {
+	store := s
+	h.touch(time.Now())
+
+	return func(ctx context.Context) {
+		now := time.Now()
+		if err := h.Overdue(now); err != nil {
+			result := h.ref.result(now)
+			result.Trace = &Trace{}
+			result.verdict(err)
+
+			save(ctx, store, &result)
+		}
+	}
+}
+
+// Ping records successful heartbeat.
+func (h *HeartbeatCheck) Ping(ctx context.Context, s Store, at time.Time) {
+	h.mu.Lock()
+	h.lastPing = at
+	h.mu.Unlock()
+
+	result := h.ref.result(at)
+	result.Trace = &Trace{}
+	result.verdict(nil)
+
+	s.Save(ctx, &result)
+}
+
+// Overdue returns error when last ping is older than period and grace time.
+func (h *HeartbeatCheck) Overdue(now time.Time) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	deadline := h.lastPing.Add(time.Duration(h.PeriodSec+h.GraceSec) * time.Second)
+	if now.After(deadline) {
+		return fmt.Errorf("Heartbeat overdue by %s", now.Sub(deadline).Truncate(time.Second))
+	}
+	return nil
+}
+
+// Authorize returns true if token matches.
+func (h *HeartbeatCheck) Authorize(token string) bool {
+	return subtle.ConstantTimeCompare([]byte(h.token), []byte(token)) == 1
+}
+
+// touch initializes last ping so grace period starts with the runner.
+func (h *HeartbeatCheck) touch(at time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.lastPing.IsZero() {
+		h.lastPing = at
+	}
+}
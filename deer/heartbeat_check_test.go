@@ -0,0 +1,108 @@
+package deer
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/franela/goblin"
+)
+
+// testStore keeps saved results in memory.
+type testStore struct {
+	mu      sync.Mutex
+	results []*CheckResult
+}
+
+func (s *testStore) Migrate(ctx context.Context) error { return nil }
+func (s *testStore) Close(ctx context.Context)         {}
+func (s *testStore) Truncate(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results = nil
+	return nil
+}
+func (s *testStore) Read(ctx context.Context, filter *ReadFilter) ([]*Metric, error) {
+	return nil, nil
+}
+func (s *testStore) Save(ctx context.Context, result *CheckResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results = append(s.results, result)
+}
+func (s *testStore) Results() []*CheckResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*CheckResult{}, s.results...)
+}
+
+func TestHeartbeatCheck(t *testing.T) {
+	g := goblin.Goblin(t)
+	g.Describe("HeartbeatCheck", func() {
+		var (
+			cfg   *Config
+			store *testStore
+		)
+
+		g.BeforeEach(func() {
+			os.Setenv("OHDEER_TEST_HEARTBEAT", "s3cr3t")
+			defer os.Unsetenv("OHDEER_TEST_HEARTBEAT")
+
+			var err error
+			cfg, err = ParseConfig("heartbeat.hcl", []byte(`
+			monitor "jobs" {
+				name = "Jobs"
+				service "backup" {
+					name = "Backup"
+					heartbeat {
+						period    = 60
+						grace     = 30
+						token_env = "OHDEER_TEST_HEARTBEAT"
+					}
+				}
+			}
+			`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			store = &testStore{}
+		})
+
+		g.It("Records success on ping", func() {
+			runner := NewRunner(cfg, store)
+
+			g.Assert(runner.Ping(context.Background(), "jobs", "backup", "s3cr3t")).IsNil()
+			g.Assert(len(store.Results())).Equal(1)
+			g.Assert(store.Results()[0].ServiceID).Equal("backup")
+			g.Assert(store.Results()[0].Success).IsTrue()
+		})
+
+		g.It("Rejects invalid token", func() {
+			runner := NewRunner(cfg, store)
+
+			g.Assert(runner.Ping(context.Background(), "jobs", "backup", "invalid")).Equal(ErrHeartbeatNotFound)
+			g.Assert(runner.Ping(context.Background(), "jobs", "other", "s3cr3t")).Equal(ErrHeartbeatNotFound)
+			g.Assert(len(store.Results())).Equal(0)
+		})
+
+		g.It("Records failure when ping is overdue", func() {
+			h := cfg.Monitors[0].Services[0].Heartbeats[0]
+			run := h.RunFn(store)
+
+			run(context.Background())
+			g.Assert(len(store.Results())).Equal(0)
+
+			h.lastPing = time.Now().Add(-100 * time.Second)
+			run(context.Background())
+			g.Assert(len(store.Results())).Equal(1)
+			g.Assert(store.Results()[0].Success).IsFalse()
+			g.Assert(store.Results()[0].Error.Error()).Equal("Heartbeat overdue by 10s")
+		})
+
+		g.It("Defaults interval to period", func() {
+			g.Assert(cfg.Monitors[0].Services[0].Heartbeats[0].Interval()).Equal(60 * time.Second)
+		})
+	})
+}
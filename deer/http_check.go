@@ -2,7 +2,15 @@ package deer
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -11,30 +19,161 @@ type HTTPCheck struct {
 	ref
 
 	// body
-	IntervalSec  uint64   `hcl:"interval"`
-	TimeoutSec   uint64   `hcl:"timeout"`
-	Addr         string   `hcl:"addr"`
-	Expectations []Expect `hcl:"expect,block"`
+	IntervalSec     float64           `hcl:"interval"`
+	TimeoutSec      uint64            `hcl:"timeout"`
+	JitterSec       uint64            `hcl:"jitter,optional"`
+	Retries         uint64            `hcl:"retries,optional"`
+	RetryDelaySec   uint64            `hcl:"retry_delay,optional"`
+	ConfirmFailures uint64            `hcl:"confirm_failures,optional"`
+	Addr            string            `hcl:"addr"`
+	Method          string            `hcl:"method,optional"`
+	Headers         map[string]string `hcl:"headers,optional"`
+	Body            string            `hcl:"body,optional"`
+	BodyFile        string            `hcl:"body_file,optional"`
+	UserAgent       string            `hcl:"user_agent,optional"`
+	FollowRedirects bool              `hcl:"follow_redirects,optional"`
+	MaxRedirects    int               `hcl:"max_redirects,optional"`
+	IPVersion       string            `hcl:"ip_version,optional"`
+	MaxBodyBytes    int64             `hcl:"max_body_bytes,optional"`
+	Auth            []Auth            `hcl:"auth,block"`
+	Expectations    []Expect          `hcl:"expect,block"`
+
+	body          []byte
+	authorization string
+	tlsConfig     *tls.Config
+
+	hashesMu sync.Mutex
+	hashes   map[string]string
 }
 
 // Validate ensures correct values are set for http check.
 func (h *HTTPCheck) Validate() error {
-	switch {
-	case h.TimeoutSec <= 0:
-		return fmt.Errorf("Timeout must be > 0")
-
-	case h.IntervalSec <= 0:
-		return fmt.Errorf("Interval must be > 0")
+	if err := validateSchedule(h.IntervalSec, h.TimeoutSec, h.JitterSec, h.retryPolicy()); err != nil {
+		return err
+	}
 
+	switch {
 	case len(h.Addr) == 0:
 		return fmt.Errorf("Addr cannot be empty")
 
 	case len(h.Expectations) == 0:
 		return fmt.Errorf("At least one expectation fot http check is required")
+
+	case len(h.Body) > 0 && len(h.BodyFile) > 0:
+		return fmt.Errorf("Body and body_file cannot be set together")
+
+	case h.MaxRedirects < 0:
+		return fmt.Errorf("Max redirects must be >= 0")
+
+	case h.MaxBodyBytes < 0:
+		return fmt.Errorf("Max body bytes must be >= 0")
+	}
+
+	if h.MaxRedirects == 0 {
+		h.MaxRedirects = 10
+	}
+	if h.MaxBodyBytes == 0 {
+		h.MaxBodyBytes = DefaultMaxBodyBytes
 	}
 
+	switch h.IPVersion {
+	case "":
+		h.IPVersion = "4"
+	case "4", "6", "both":
+	default:
+		return fmt.Errorf("IP version must be one of 4, 6 or both")
+	}
+
+	h.Method = strings.ToUpper(h.Method)
+	if h.Method == "" {
+		h.Method = http.MethodGet
+	}
+
+	h.body = []byte(h.Body)
+	if len(h.BodyFile) > 0 {
+		b, err := ioutil.ReadFile(h.BodyFile)
+		if err != nil {
+			return fmt.Errorf("Cannot read body_file: %v", err)
+		}
+		h.body = b
+	}
+
+	h.authorization = ""
+	h.tlsConfig = nil
+	for _, auth := range h.Auth {
+		var err error
+
+		switch auth.Type {
+		case "basic", "bearer":
+			if h.authorization != "" {
+				return fmt.Errorf("Only one of basic or bearer auth can be set")
+			}
+			h.authorization, err = auth.authorization()
+		case "tls":
+			h.tlsConfig, err = auth.tlsConfig()
+		default:
+			err = fmt.Errorf("Invalid auth type")
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	bodyHashes := 0
 	for _, expect := range h.Expectations {
-		if expect.Subject != "status" {
+		switch expect.Subject {
+		case "status":
+			if len(expect.Inclusion) == 0 {
+				return fmt.Errorf("Status expectation requires at least one value in")
+			}
+		case "body":
+			if err := expect.validateMatcher("Body"); err != nil {
+				return err
+			}
+		case "header":
+			if len(expect.Name) == 0 {
+				return fmt.Errorf("Header expectation requires name")
+			}
+			if expect.Present && expect.Absent {
+				return fmt.Errorf("Header expectation cannot be both present and absent")
+			}
+			if _, err := regexp.Compile(expect.Matches); err != nil {
+				return fmt.Errorf("Invalid header regexp: %v", err)
+			}
+		case "latency":
+			if len(expect.latencyLimits(&Trace{})) == 0 {
+				return fmt.Errorf("Latency expectation requires at least one limit")
+			}
+		case "final_url":
+			if err := expect.validateMatcher("Final URL"); err != nil {
+				return err
+			}
+		case "json":
+			if len(expect.Path) == 0 {
+				return fmt.Errorf("JSON expectation requires path")
+			}
+			if _, err := regexp.Compile(expect.Matches); err != nil {
+				return fmt.Errorf("Invalid json regexp: %v", err)
+			}
+		case "size":
+			if expect.Min == nil && expect.Max == nil {
+				return fmt.Errorf("Size expectation requires min or max")
+			}
+		case "body_hash":
+			// response keeps single hash computed with the expectation ignore regexp
+			if bodyHashes++; bodyHashes > 1 {
+				return fmt.Errorf("Only one body hash expectation can be set")
+			}
+			if expect.Equals != "" {
+				if b, err := hex.DecodeString(expect.Equals); err != nil || len(b) != sha256.Size {
+					return fmt.Errorf("Body hash must be sha256 hex digest")
+				}
+			}
+			if _, err := regexp.Compile(expect.Ignore); err != nil {
+				return fmt.Errorf("Invalid body hash ignore regexp: %v", err)
+			}
+		default:
 			return fmt.Errorf("Invalid expectation subject")
 		}
 	}
@@ -42,39 +181,137 @@ func (h *HTTPCheck) Validate() error {
 	return nil
 }
 
+// Interval returns how often check should be run.
+func (h *HTTPCheck) Interval() time.Duration {
+	return seconds(h.IntervalSec)
+}
+
+// Jitter returns maximum random delay added to each run.
+func (h *HTTPCheck) Jitter() time.Duration {
+	return time.Duration(h.JitterSec) * time.Second
+}
+
+// target returns addr, from which check ID is derived.
+func (h *HTTPCheck) target() string {
+	return h.Addr
+}
+
+// retryPolicy returns how failed probes are retried.
+func (h *HTTPCheck) retryPolicy() retryPolicy {
+	return retryPolicy{h.Retries, h.RetryDelaySec, h.ConfirmFailures}
+}
+
 // RunFn returns task function to run check.
-func (h *HTTPCheck) RunFn(s Store) func() {
+// When both ip versions are configured, check is run and saved separately for each of them.
+func (h *HTTPCheck) RunFn(s Store) func(context.Context) {
 	store := s
 
-	return func() {
-		now := time.Now()
-		req := Request{}
-		resp := req.Get(h.Addr, time.Duration(h.TimeoutSec)*time.Second)
-
-		success := h.Check(resp)
-		result := CheckResult{
-			MonitorID: h.ref.Monitor.ID,
-			ServiceID: h.ref.Service.ID,
-			At:        now,
-			Success:   success,
-			Trace:     &resp.Trace,
-			Error:     resp.Err,
+	return func(ctx context.Context) {
+		for _, ipVersion := range h.IPVersions() {
+			h.ref.run(ctx, store, h.retryPolicy(), ipVersion, func() CheckResult {
+				now := time.Now()
+				req := h.Request()
+				req.Network = "tcp" + ipVersion
+				resp := req.Do(ctx, h.Addr, time.Duration(h.TimeoutSec)*time.Second)
+				h.TrackChange(ipVersion, resp)
+
+				result := h.ref.result(now)
+				result.Trace = &resp.Trace
+				result.IPVersion = ipVersion
+				result.verdict(h.Verify(resp))
+				if resp.Resp != nil {
+					result.StatusCode = resp.Resp.StatusCode
+					result.Details = &Details{Response: &ResponseDetails{
+						FinalURL:  resp.Resp.Request.URL.String(),
+						Redirects: resp.Redirects,
+						BodySize:  len(resp.Body),
+						BodyHash:  resp.BodyHash,
+						Changed:   resp.Changed,
+					}}
+				}
+
+				return result
+			})
 		}
-		if resp.Resp != nil {
-			result.StatusCode = resp.Resp.StatusCode
+	}
+}
+
+// IPVersions returns list of ip versions the check is run over.
+func (h *HTTPCheck) IPVersions() []string {
+	if h.IPVersion == "both" {
+		return []string{"4", "6"}
+	}
+	if h.IPVersion == "" {
+		return []string{"4"}
+	}
+	return []string{h.IPVersion}
+}
+
+// TrackChange hashes normalized body when body_hash expectation is set
+// and marks response as changed when hash differs from the previous run.
+// Hashes are kept in memory per ip version, so the first run after start is never a change.
+func (h *HTTPCheck) TrackChange(ipVersion string, resp *Response) {
+	if resp.Err != nil {
+		return
+	}
+
+	for _, expect := range h.Expectations {
+		if expect.Subject != "body_hash" {
+			continue
 		}
+		resp.BodyHash = bodyHash(resp.Body, expect.Ignore)
 
-		store.Save(context.Background(), &result)
+		h.hashesMu.Lock()
+		defer h.hashesMu.Unlock()
+
+		if h.hashes == nil {
+			h.hashes = map[string]string{}
+		}
+		previous, ok := h.hashes[ipVersion]
+		resp.Changed = ok && previous != resp.BodyHash
+		h.hashes[ipVersion] = resp.BodyHash
+		return
 	}
 }
 
+// Request builds request with configured method, headers and body.
+func (h *HTTPCheck) Request() *Request {
+	req := Request{
+		Method:          h.Method,
+		Header:          http.Header{},
+		Body:            h.body,
+		TLSConfig:       h.tlsConfig,
+		FollowRedirects: h.FollowRedirects,
+		MaxRedirects:    h.MaxRedirects,
+		MaxBodyBytes:    h.MaxBodyBytes,
+	}
+	for k, v := range h.Headers {
+		req.Header.Set(k, v)
+	}
+	if h.UserAgent != "" {
+		req.Header.Set("User-Agent", h.UserAgent)
+	}
+	if h.authorization != "" {
+		req.Header.Set("Authorization", h.authorization)
+	}
+
+	return &req
+}
+
 // Check verifies if check is valid or not.
 func (h *HTTPCheck) Check(resp *Response) bool {
+	err := h.Verify(resp)
+	_, degraded := err.(*DegradedError)
+	return err == nil || degraded
+}
+
+// Verify returns the reason why check is not valid or nil when it passes.
+func (h *HTTPCheck) Verify(resp *Response) error {
 	if resp.Err != nil {
-		return false
+		return resp.Err
 	}
 
-	success := true
+	var degraded error
 
 	for _, expect := range h.Expectations {
 		switch expect.Subject {
@@ -87,8 +324,112 @@ func (h *HTTPCheck) Check(resp *Response) bool {
 				}
 			}
 
-			success = success && found
+			if !found {
+				return fmt.Errorf("Status %d is not in %v", status, expect.Inclusion)
+			}
+
+		case "body":
+			if err := expect.verifyString("Body", string(resp.Body)); err != nil {
+				return err
+			}
+
+		case "size":
+			if err := expect.verifyRange("Body size", len(resp.Body)); err != nil {
+				return err
+			}
+
+		case "json":
+			value, err := lookupJSONPath(resp.Body, expect.Path)
+			if err != nil {
+				return err
+			}
+			if err := expect.verifyString("JSON "+expect.Path, value); err != nil {
+				return err
+			}
+
+		case "header":
+			if err := verifyHeader(expect, resp.Resp.Header); err != nil {
+				return err
+			}
+
+		case "final_url":
+			if err := expect.verifyString("Final URL", resp.Resp.Request.URL.String()); err != nil {
+				return err
+			}
+
+		case "body_hash":
+			err := verifyBodyHash(expect, resp)
+			if err != nil && expect.Degrade {
+				if degraded == nil {
+					degraded = &DegradedError{Reason: err}
+				}
+			} else if err != nil {
+				return err
+			}
+
+		case "latency":
+			err := expect.verifyLatency(&resp.Trace)
+			if _, ok := err.(*DegradedError); ok {
+				if degraded == nil {
+					degraded = err
+				}
+			} else if err != nil {
+				return err
+			}
 		}
 	}
-	return success
+	return degraded
+}
+
+func verifyBodyHash(expect Expect, resp *Response) error {
+	hash := resp.BodyHash
+	if hash == "" {
+		hash = bodyHash(resp.Body, expect.Ignore)
+	}
+
+	switch {
+	case expect.Equals != "" && !strings.EqualFold(hash, expect.Equals):
+		return fmt.Errorf("Body hash %s does not equal %s", hash, expect.Equals)
+
+	case expect.Equals == "" && resp.Changed:
+		// change is reported once, retrying or waiting for confirmation would hide it
+		return &permanentError{fmt.Errorf("Body changed (hash %s)", hash)}
+	}
+	return nil
+}
+
+// bodyHash returns sha256 hex digest of normalized body.
+// Line endings and surrounding whitespace are normalized and parts matching ignore regexp are removed,
+// so that insignificant differences (e.g. timestamps) do not count as a change.
+func bodyHash(body []byte, ignore string) string {
+	s := strings.ReplaceAll(string(body), "\r\n", "\n")
+	if ignore != "" {
+		s = regexp.MustCompile(ignore).ReplaceAllString(s, "")
+	}
+
+	lines := strings.Split(s, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	s = strings.TrimSpace(strings.Join(lines, "\n"))
+
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func verifyHeader(expect Expect, header http.Header) error {
+	values, present := header[http.CanonicalHeaderKey(expect.Name)]
+
+	switch {
+	case expect.Absent && present:
+		return fmt.Errorf("Header %s is present", expect.Name)
+
+	case expect.Absent:
+		return nil
+
+	case !present:
+		return fmt.Errorf("Header %s is missing", expect.Name)
+	}
+
+	return expect.verifyString("Header "+expect.Name, strings.Join(values, ", "))
 }
@@ -0,0 +1,468 @@
+package deer
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/franela/goblin"
+)
+
+func TestHTTPCheck(t *testing.T) {
+	g := goblin.Goblin(t)
+	g.Describe("HTTPCheck", func() {
+		var srv *httptest.Server
+
+		g.Before(func() {
+			srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Strict-Transport-Security", "max-age=31536000; includeSubDomains")
+				w.Write([]byte(`{"db":"ok","checks":[{"name":"cache","up":true}]}`))
+			}))
+		})
+
+		g.After(func() {
+			srv.Close()
+		})
+
+		get := func() *Response {
+			req := Request{}
+			return req.Get(context.Background(), srv.URL, time.Second)
+		}
+
+		g.Describe("Request", func() {
+			var echo *httptest.Server
+
+			g.Before(func() {
+				echo = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					body, _ := ioutil.ReadAll(r.Body)
+					fmt.Fprintf(w, "%s %s %s %s", r.Method, r.UserAgent(), r.Header.Get("X-Api-Key"), body)
+				}))
+			})
+
+			g.After(func() {
+				echo.Close()
+			})
+
+			g.It("Sends default user agent", func() {
+				check := &HTTPCheck{}
+				resp := check.Request().Do(context.Background(), echo.URL, time.Second)
+
+				g.Assert(string(resp.Body)).Equal("GET OhDeer/0.0.1  ")
+			})
+
+			g.It("Sends configured method, headers and body", func() {
+				check := &HTTPCheck{
+					Method:    "POST",
+					Headers:   map[string]string{"x-api-key": "secret"},
+					UserAgent: "Probe/1.0",
+					body:      []byte(`{"query":"{ health }"}`),
+				}
+				resp := check.Request().Do(context.Background(), echo.URL, time.Second)
+
+				g.Assert(string(resp.Body)).Equal(`POST Probe/1.0 secret {"query":"{ health }"}`)
+			})
+		})
+
+		g.Describe("IP version", func() {
+			g.It("Dials over configured network", func() {
+				req := Request{Network: "tcp4"}
+				g.Assert(req.Do(context.Background(), srv.URL, time.Second).Err).IsNil()
+
+				req = Request{Network: "tcp6"}
+				g.Assert(req.Do(context.Background(), srv.URL, time.Second).Err == nil).IsFalse()
+			})
+
+			g.It("Runs over both versions", func() {
+				check := &HTTPCheck{IPVersion: "both"}
+				g.Assert(check.IPVersions()).Equal([]string{"4", "6"})
+
+				check = &HTTPCheck{}
+				g.Assert(check.IPVersions()).Equal([]string{"4"})
+			})
+		})
+
+		g.Describe("Redirects", func() {
+			var redirects *httptest.Server
+
+			g.Before(func() {
+				mux := http.NewServeMux()
+				mux.Handle("/a", http.RedirectHandler("/b", http.StatusMovedPermanently))
+				mux.Handle("/b", http.RedirectHandler("/c", http.StatusFound))
+				mux.HandleFunc("/c", func(w http.ResponseWriter, r *http.Request) {})
+				redirects = httptest.NewServer(mux)
+			})
+
+			g.After(func() {
+				redirects.Close()
+			})
+
+			g.It("Does not follow redirects by default", func() {
+				check := &HTTPCheck{Expectations: []Expect{{Subject: "status", Inclusion: []int{301}}}}
+				resp := check.Request().Do(context.Background(), redirects.URL+"/a", time.Second)
+
+				g.Assert(check.Verify(resp)).IsNil()
+				g.Assert(len(resp.Redirects)).Equal(0)
+			})
+
+			g.It("Follows redirects and records the chain", func() {
+				check := &HTTPCheck{
+					FollowRedirects: true,
+					MaxRedirects:    5,
+					Expectations: []Expect{
+						{Subject: "status", Inclusion: []int{200}},
+						{Subject: "final_url", Equals: redirects.URL + "/c"},
+					},
+				}
+				resp := check.Request().Do(context.Background(), redirects.URL+"/a", time.Second)
+
+				g.Assert(check.Verify(resp)).IsNil()
+				g.Assert(len(resp.Redirects)).Equal(2)
+				g.Assert(resp.Redirects[0].URL).Equal(redirects.URL + "/a")
+				g.Assert(resp.Redirects[0].StatusCode).Equal(301)
+				g.Assert(resp.Redirects[1].URL).Equal(redirects.URL + "/b")
+				g.Assert(resp.Redirects[1].StatusCode).Equal(302)
+			})
+
+			g.It("Fails when max redirects is exceeded", func() {
+				check := &HTTPCheck{
+					FollowRedirects: true,
+					MaxRedirects:    1,
+					Expectations:    []Expect{{Subject: "status", Inclusion: []int{200}}},
+				}
+				resp := check.Request().Do(context.Background(), redirects.URL+"/a", time.Second)
+
+				g.Assert(check.Check(resp)).IsFalse()
+			})
+		})
+
+		g.Describe("Body", func() {
+			g.It("Passes when body matches", func() {
+				check := &HTTPCheck{Expectations: []Expect{
+					{Subject: "body", Contains: `"db":"ok"`, NotContains: "error", Matches: `^\{.*\}$`},
+				}}
+
+				g.Assert(check.Check(get())).IsTrue()
+			})
+
+			g.It("Fails when body contains forbidden text", func() {
+				check := &HTTPCheck{Expectations: []Expect{
+					{Subject: "body", NotContains: "cache"},
+				}}
+
+				g.Assert(check.Check(get())).IsFalse()
+			})
+		})
+
+		g.Describe("Header", func() {
+			g.It("Passes when headers match", func() {
+				check := &HTTPCheck{Expectations: []Expect{
+					{Subject: "header", Name: "content-type", Equals: "application/json"},
+					{Subject: "header", Name: "Strict-Transport-Security", Matches: `max-age=\d+`},
+					{Subject: "header", Name: "X-Powered-By", Absent: true},
+					{Subject: "header", Name: "Date", Present: true},
+				}}
+
+				g.Assert(check.Verify(get())).IsNil()
+			})
+
+			g.It("Reports missing header", func() {
+				check := &HTTPCheck{Expectations: []Expect{
+					{Subject: "header", Name: "Content-Security-Policy", Present: true},
+				}}
+
+				g.Assert(check.Verify(get()).Error()).Equal("Header Content-Security-Policy is missing")
+			})
+
+			g.It("Reports mismatched header", func() {
+				check := &HTTPCheck{Expectations: []Expect{
+					{Subject: "header", Name: "Content-Type", Contains: "text/html"},
+				}}
+
+				g.Assert(check.Verify(get()).Error()).Equal(`Header Content-Type "application/json" does not contain "text/html"`)
+			})
+
+			g.It("Reports unexpected header", func() {
+				check := &HTTPCheck{Expectations: []Expect{
+					{Subject: "header", Name: "Content-Type", Absent: true},
+				}}
+
+				g.Assert(check.Verify(get()).Error()).Equal("Header Content-Type is present")
+			})
+		})
+
+		g.Describe("Latency", func() {
+			slow := &Response{
+				Resp: &http.Response{StatusCode: 200},
+				Trace: Trace{
+					ServerProcessing: 350 * time.Millisecond,
+					Total:            400 * time.Millisecond,
+				},
+			}
+			limit := func(ms int) *int { return &ms }
+
+			g.It("Passes when fast enough", func() {
+				check := &HTTPCheck{Expectations: []Expect{
+					{Subject: "latency", TotalMs: limit(800), ServerProcessingMs: limit(500)},
+				}}
+
+				g.Assert(check.Verify(slow)).IsNil()
+			})
+
+			g.It("Fails when too slow", func() {
+				check := &HTTPCheck{Expectations: []Expect{
+					{Subject: "latency", TotalMs: limit(800), ServerProcessingMs: limit(300)},
+				}}
+				err := check.Verify(slow)
+
+				g.Assert(err.Error()).Equal("Server processing took 350ms (max 300ms)")
+				g.Assert(check.Check(slow)).IsFalse()
+			})
+
+			g.It("Marks result degraded", func() {
+				check := &HTTPCheck{Expectations: []Expect{
+					{Subject: "latency", TotalMs: limit(300), Degrade: true},
+					{Subject: "status", Inclusion: []int{200}},
+				}}
+				var result CheckResult
+				result.verdict(check.Verify(slow))
+
+				g.Assert(check.Check(slow)).IsTrue()
+				g.Assert(result.Success).IsTrue()
+				g.Assert(result.Degraded).IsTrue()
+				g.Assert(result.Error.Error()).Equal("Total took 400ms (max 300ms)")
+			})
+
+			g.It("Prefers failure over degradation", func() {
+				check := &HTTPCheck{Expectations: []Expect{
+					{Subject: "latency", TotalMs: limit(300), Degrade: true},
+					{Subject: "status", Inclusion: []int{204}},
+				}}
+
+				g.Assert(check.Check(slow)).IsFalse()
+			})
+		})
+
+		g.Describe("JSON", func() {
+			g.It("Passes when value under path matches", func() {
+				check := &HTTPCheck{Expectations: []Expect{
+					{Subject: "json", Path: "db", Equals: "ok"},
+					{Subject: "json", Path: "$.checks[0].up", Equals: "true"},
+				}}
+
+				g.Assert(check.Check(get())).IsTrue()
+			})
+
+			g.It("Fails when path is missing", func() {
+				check := &HTTPCheck{Expectations: []Expect{
+					{Subject: "json", Path: "checks.1.up"},
+				}}
+
+				g.Assert(check.Check(get())).IsFalse()
+			})
+
+			g.It("Fails when value differs", func() {
+				check := &HTTPCheck{Expectations: []Expect{
+					{Subject: "json", Path: "db", Equals: "down"},
+				}}
+
+				g.Assert(check.Check(get())).IsFalse()
+			})
+		})
+
+		g.Describe("Size", func() {
+			g.It("Passes when body size is in range", func() {
+				min, max := 10, 100
+				check := &HTTPCheck{Expectations: []Expect{
+					{Subject: "size", Min: &min, Max: &max},
+				}}
+
+				g.Assert(check.Check(get())).IsTrue()
+			})
+
+			g.It("Fails when body is too small", func() {
+				min := 1000
+				check := &HTTPCheck{Expectations: []Expect{
+					{Subject: "size", Min: &min},
+				}}
+
+				g.Assert(check.Verify(get()).Error()).Equal("Body size 49 is lower than 1000")
+			})
+
+			g.It("Stops reading body at the cap", func() {
+				req := Request{MaxBodyBytes: 8}
+				resp := req.Get(context.Background(), srv.URL, time.Second)
+
+				g.Assert(resp.Err.Error()).Equal("Body exceeds 8 bytes")
+				g.Assert(string(resp.Body)).Equal(`{"db":"o`)
+			})
+		})
+
+		g.Describe("Body hash", func() {
+			g.It("Normalizes body before hashing", func() {
+				g.Assert(bodyHash([]byte("  a\r\nb  \n"), "")).Equal(bodyHash([]byte("a\nb"), ""))
+				g.Assert(bodyHash([]byte("updated 12:01\nok"), `\d+:\d+`)).Equal(bodyHash([]byte("updated 12:02\nok"), `\d+:\d+`))
+				g.Assert(bodyHash([]byte("ok"), "") == bodyHash([]byte("down"), "")).IsFalse()
+			})
+
+			g.It("Passes when body matches pinned hash", func() {
+				check := &HTTPCheck{Expectations: []Expect{
+					{Subject: "body_hash", Equals: bodyHash([]byte(`{"db":"ok","checks":[{"name":"cache","up":true}]}`), "")},
+				}}
+
+				g.Assert(check.Check(get())).IsTrue()
+
+				check.Expectations[0].Equals = bodyHash([]byte("other"), "")
+				g.Assert(check.Check(get())).IsFalse()
+			})
+
+			g.It("Detects change since previous run", func() {
+				check := &HTTPCheck{Expectations: []Expect{{Subject: "body_hash"}}}
+
+				resp := get()
+				check.TrackChange("4", resp)
+				g.Assert(resp.Changed).IsFalse()
+				g.Assert(check.Check(resp)).IsTrue()
+
+				resp = get()
+				check.TrackChange("4", resp)
+				g.Assert(resp.Changed).IsFalse()
+
+				resp = get()
+				resp.Body = []byte(`{"db":"down"}`)
+				check.TrackChange("4", resp)
+				g.Assert(resp.Changed).IsTrue()
+				g.Assert(check.Verify(resp).Error()).Equal("Body changed (hash " + resp.BodyHash + ")")
+			})
+
+			g.It("Marks change as degraded", func() {
+				check := &HTTPCheck{Expectations: []Expect{{Subject: "body_hash", Degrade: true}}}
+
+				check.TrackChange("4", get())
+				resp := get()
+				resp.Body = []byte("changed")
+				check.TrackChange("4", resp)
+
+				_, degraded := check.Verify(resp).(*DegradedError)
+				g.Assert(degraded).IsTrue()
+				g.Assert(check.Check(resp)).IsTrue()
+			})
+		})
+
+		g.Describe("Retries", func() {
+			var (
+				flaky    *httptest.Server
+				failures int
+				requests int
+			)
+
+			g.BeforeEach(func() {
+				requests = 0
+				flaky = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					requests++
+					if requests <= failures {
+						w.WriteHeader(http.StatusServiceUnavailable)
+					}
+				}))
+			})
+
+			g.AfterEach(func() {
+				flaky.Close()
+			})
+
+			run := func(check *HTTPCheck, runs int) *testStore {
+				check.bind(&Monitor{ID: "aws"}, &Service{ID: "api"}, "aws/api/http/0")
+				check.IntervalSec, check.TimeoutSec, check.Addr = 60, 1, flaky.URL
+				check.Expectations = []Expect{{Subject: "status", Inclusion: []int{200}}}
+				if err := check.Validate(); err != nil {
+					t.Fatal(err)
+				}
+
+				store := &testStore{}
+				fn := check.RunFn(store)
+				for i := 0; i < runs; i++ {
+					fn(context.Background())
+				}
+				return store
+			}
+
+			g.It("Saves success after retried failure with attempt count", func() {
+				failures = 2
+				store := run(&HTTPCheck{Retries: 2}, 1)
+
+				g.Assert(requests).Equal(3)
+				g.Assert(len(store.Results())).Equal(1)
+				g.Assert(store.Results()[0].Success).IsTrue()
+				g.Assert(store.Results()[0].Details.Attempts).Equal(3)
+			})
+
+			g.It("Saves failure when retries are exhausted", func() {
+				failures = 5
+				store := run(&HTTPCheck{Retries: 1}, 1)
+
+				g.Assert(requests).Equal(2)
+				g.Assert(store.Results()[0].Success).IsFalse()
+				g.Assert(store.Results()[0].StatusCode).Equal(http.StatusServiceUnavailable)
+				g.Assert(store.Results()[0].Details.Attempts).Equal(2)
+			})
+
+			g.It("Saves failure as degraded until confirmed", func() {
+				failures = 2
+				store := run(&HTTPCheck{ConfirmFailures: 2}, 4)
+				results := store.Results()
+
+				g.Assert(results[0].Success).IsTrue()
+				g.Assert(results[0].Degraded).IsTrue()
+				g.Assert(results[0].Error.Error()).Equal("Unconfirmed failure 1/2: Status 503 is not in [200]")
+				g.Assert(results[1].Success).IsFalse()
+				g.Assert(results[2].Success).IsTrue()
+				g.Assert(results[2].Degraded).IsFalse()
+				g.Assert(results[0].Details.Attempts).Equal(0)
+			})
+
+			g.It("Saves body change right away", func() {
+				changing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					requests++
+					fmt.Fprintf(w, "version %d", requests)
+				}))
+				defer changing.Close()
+
+				check := &HTTPCheck{
+					IntervalSec:     60,
+					TimeoutSec:      1,
+					Addr:            changing.URL,
+					Retries:         2,
+					ConfirmFailures: 3,
+					Expectations:    []Expect{{Subject: "body_hash"}},
+				}
+				check.bind(&Monitor{ID: "aws"}, &Service{ID: "api"}, "aws/api/http/0")
+				g.Assert(check.Validate()).IsNil()
+				store := &testStore{}
+				fn := check.RunFn(store)
+				fn(context.Background())
+				fn(context.Background())
+				results := store.Results()
+
+				g.Assert(results[0].Success).IsTrue()
+				g.Assert(results[1].Success).IsFalse()
+				g.Assert(results[1].Degraded).IsFalse()
+				g.Assert(results[1].Details.Attempts).Equal(1)
+				g.Assert(results[1].Details.Response.Changed).IsTrue()
+			})
+
+			g.It("Resets unconfirmed failures on success", func() {
+				failures = 1
+				store := run(&HTTPCheck{ConfirmFailures: 2}, 2)
+				results := store.Results()
+
+				g.Assert(results[0].Degraded).IsTrue()
+				g.Assert(results[1].Degraded).IsFalse()
+				g.Assert(results[1].Error).IsNil()
+			})
+		})
+	})
+}
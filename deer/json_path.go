@@ -0,0 +1,62 @@
+package deer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// lookupJSONPath finds value in json document using dot notation path,
+// e.g. "db", "checks.0.status" or "$.checks[0].status".
+// Strings are returned as is, other values are returned json encoded.
+func lookupJSONPath(doc []byte, path string) (string, error) {
+	var v interface{}
+
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return "", err
+	}
+
+	for _, key := range splitJSONPath(path) {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			child, ok := node[key]
+			if !ok {
+				return "", fmt.Errorf("Path %s not found", path)
+			}
+			v = child
+
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return "", fmt.Errorf("Path %s not found", path)
+			}
+			v = node[i]
+
+		default:
+			return "", fmt.Errorf("Path %s not found", path)
+		}
+	}
+
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+func splitJSONPath(path string) []string {
+	path = strings.TrimPrefix(path, "$")
+	path = strings.NewReplacer("[", ".", "]", "").Replace(path)
+
+	keys := make([]string, 0)
+	for _, key := range strings.Split(path, ".") {
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,154 @@
+package deer
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RunnerStats contains probe execution statistics.
+type RunnerStats struct {
+	// Running is number of probes being run.
+	Running int `json:"running"`
+	// Queued is number of probes waiting for free slot.
+	Queued int `json:"queued"`
+	// Runs is number of probes started since start.
+	Runs uint64 `json:"runs"`
+	// Skipped is number of runs skipped because check was still running from the previous tick.
+	Skipped uint64 `json:"skipped"`
+	// QueueWaitAvgMs and QueueWaitMaxMs are average and maximum time probes waited for a slot.
+	QueueWaitAvgMs int64 `json:"queue_wait_avg_ms"`
+	QueueWaitMaxMs int64 `json:"queue_wait_max_ms"`
+}
+
+// limiter bounds number of probes run at the same time, globally and per monitor.
+// Probes over the limit wait in the queue until a slot is released.
+type limiter struct {
+	clock Clock
+
+	mu        sync.Mutex
+	global    chan struct{}
+	monitors  map[string]chan struct{}
+	stats     RunnerStats
+	waitTotal time.Duration
+	waitMax   time.Duration
+}
+
+func newLimiter(cfg *Config, clock Clock) *limiter {
+	l := limiter{clock: clock}
+	l.configure(cfg)
+
+	return &l
+}
+
+// configure applies limits from config.
+// Slots of unchanged limits are kept, probes holding slots of changed ones release them to the old pool.
+func (l *limiter) configure(cfg *Config) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	max := 0
+	if cfg.Runner != nil {
+		max = cfg.Runner.MaxConcurrency
+	}
+	l.global = resize(l.global, max)
+
+	monitors := map[string]chan struct{}{}
+	for _, m := range cfg.Monitors {
+		if sem := resize(l.monitors[m.ID], m.MaxConcurrency); sem != nil {
+			monitors[m.ID] = sem
+		}
+	}
+	l.monitors = monitors
+}
+
+// wrap returns fn which waits for free slot before running.
+// When ctx is cancelled while waiting, fn is not run at all.
+func (l *limiter) wrap(monitorID string, fn func(context.Context)) func(context.Context) {
+	return func(ctx context.Context) {
+		var global, monitor chan struct{}
+		l.update(func(s *RunnerStats) {
+			s.Queued++
+			global, monitor = l.global, l.monitors[monitorID]
+		})
+		start := l.clock.Now()
+
+		// monitor slot is taken first, so that waiting for it does not hold the global one
+		if !acquire(ctx, monitor) {
+			l.update(func(s *RunnerStats) { s.Queued-- })
+			return
+		}
+		defer release(monitor)
+		if !acquire(ctx, global) {
+			l.update(func(s *RunnerStats) { s.Queued-- })
+			return
+		}
+		defer release(global)
+
+		wait := l.clock.Now().Sub(start)
+		l.update(func(s *RunnerStats) {
+			s.Queued--
+			s.Running++
+			s.Runs++
+			l.waitTotal += wait
+			if wait > l.waitMax {
+				l.waitMax = wait
+			}
+		})
+		defer l.update(func(s *RunnerStats) { s.Running-- })
+
+		fn(ctx)
+	}
+}
+
+// Stats returns snapshot of execution statistics.
+func (l *limiter) Stats() RunnerStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stats := l.stats
+	stats.QueueWaitMaxMs = l.waitMax.Milliseconds()
+	if stats.Runs > 0 {
+		stats.QueueWaitAvgMs = (l.waitTotal / time.Duration(stats.Runs)).Milliseconds()
+	}
+	return stats
+}
+
+func (l *limiter) update(fn func(s *RunnerStats)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fn(&l.stats)
+}
+
+// acquire takes slot from semaphore, nil semaphore means no limit.
+// Returns false when ctx is cancelled before the slot is taken.
+func acquire(ctx context.Context, sem chan struct{}) bool {
+	if sem == nil {
+		return true
+	}
+
+	select {
+	case sem <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// resize returns semaphore with given capacity, reusing the current one when capacity is the same.
+// Zero capacity means no limit.
+func resize(sem chan struct{}, capacity int) chan struct{} {
+	switch {
+	case capacity <= 0:
+		return nil
+	case sem != nil && cap(sem) == capacity:
+		return sem
+	}
+	return make(chan struct{}, capacity)
+}
+
+func release(sem chan struct{}) {
+	if sem != nil {
+		<-sem
+	}
+}
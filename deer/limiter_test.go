@@ -0,0 +1,107 @@
+package deer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/franela/goblin"
+)
+
+func TestLimiter(t *testing.T) {
+	g := goblin.Goblin(t)
+	g.Describe("limiter", func() {
+		blocking := func(started chan string, name string, done chan bool) func(context.Context) {
+			return func(ctx context.Context) {
+				started <- name
+				<-done
+			}
+		}
+
+		waitQueued := func(l *limiter, n int) {
+			for l.Stats().Queued < n {
+				time.Sleep(time.Millisecond)
+			}
+		}
+
+		g.It("Runs probes without limit", func() {
+			l := newLimiter(&Config{}, newFakeClock())
+			started := make(chan string, 2)
+			done := make(chan bool)
+
+			go l.wrap("aws", blocking(started, "a", done))(context.Background())
+			go l.wrap("aws", blocking(started, "b", done))(context.Background())
+			<-started
+			<-started
+
+			g.Assert(l.Stats().Running).Equal(2)
+			close(done)
+		})
+
+		g.It("Queues probes over global limit", func() {
+			clock := newFakeClock()
+			l := newLimiter(&Config{Runner: &RunnerConfig{MaxConcurrency: 1}}, clock)
+			started := make(chan string, 2)
+			done := make(chan bool)
+
+			go l.wrap("aws", blocking(started, "a", done))(context.Background())
+			g.Assert(<-started).Equal("a")
+			go l.wrap("gcp", blocking(started, "b", done))(context.Background())
+			waitQueued(l, 1)
+
+			stats := l.Stats()
+			g.Assert(stats.Running).Equal(1)
+			g.Assert(stats.Queued).Equal(1)
+
+			clock.Advance(3 * time.Second)
+			done <- true
+			g.Assert(<-started).Equal("b")
+
+			stats = l.Stats()
+			g.Assert(stats.Runs).Equal(uint64(2))
+			g.Assert(stats.Queued).Equal(0)
+			g.Assert(stats.QueueWaitMaxMs).Equal(int64(3000))
+			g.Assert(stats.QueueWaitAvgMs).Equal(int64(1500))
+			close(done)
+		})
+
+		g.It("Limits probes per monitor", func() {
+			l := newLimiter(&Config{Monitors: []*Monitor{{ID: "aws", MaxConcurrency: 1}}}, newFakeClock())
+			started := make(chan string, 3)
+			done := make(chan bool)
+
+			go l.wrap("aws", blocking(started, "a", done))(context.Background())
+			g.Assert(<-started).Equal("a")
+			go l.wrap("aws", blocking(started, "b", done))(context.Background())
+			waitQueued(l, 1)
+			go l.wrap("gcp", blocking(started, "c", done))(context.Background())
+			g.Assert(<-started).Equal("c")
+
+			g.Assert(l.Stats().Running).Equal(2)
+			g.Assert(l.Stats().Queued).Equal(1)
+			close(done)
+		})
+
+		g.It("Drops queued probe when context is cancelled", func() {
+			l := newLimiter(&Config{Runner: &RunnerConfig{MaxConcurrency: 1}}, newFakeClock())
+			started := make(chan string, 2)
+			done := make(chan bool)
+			ctx, cancel := context.WithCancel(context.Background())
+
+			go l.wrap("aws", blocking(started, "a", done))(context.Background())
+			<-started
+			returned := make(chan bool)
+			go func() {
+				l.wrap("aws", blocking(started, "b", done))(ctx)
+				returned <- true
+			}()
+			waitQueued(l, 1)
+			cancel()
+
+			g.Assert(<-returned).IsTrue()
+			g.Assert(l.Stats().Queued).Equal(0)
+			g.Assert(l.Stats().Runs).Equal(uint64(1))
+			close(done)
+		})
+	})
+}
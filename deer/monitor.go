@@ -1,5 +1,10 @@
 package deer
 
+import (
+	"sort"
+	"time"
+)
+
 // Validatable interface.
 type Validatable interface {
 	Validate() error
@@ -12,8 +17,10 @@ type Monitor struct {
 	ID string `hcl:"id,label"`
 
 	// body
-	Name     string     `hcl:"name"`
-	Services []*Service `hcl:"service,block"`
+	Name string `hcl:"name"`
+	// MaxConcurrency limits number of probes run at the same time for the monitor, 0 means no limit.
+	MaxConcurrency int        `hcl:"max_concurrency,optional"`
+	Services       []*Service `hcl:"service,block"`
 }
 
 // Service defines monitor checks.
@@ -22,11 +29,116 @@ type Service struct {
 	ID string `hcl:"id,label"`
 
 	// body
-	Name       string       `hcl:"name"`
-	HTTPChecks []*HTTPCheck `hcl:"http,block"`
+	Name       string            `hcl:"name"`
+	HTTPChecks []*HTTPCheck      `hcl:"http,block"`
+	TCPChecks  []*TCPCheck       `hcl:"tcp,block"`
+	TLSChecks  []*TLSCheck       `hcl:"tls,block"`
+	DNSChecks  []*DNSCheck       `hcl:"dns,block"`
+	Heartbeats []*HeartbeatCheck `hcl:"heartbeat,block"`
+	ExecChecks []*ExecCheck      `hcl:"exec,block"`
+	Postgres   []*SQLCheck       `hcl:"postgres,block"`
+	MySQL      []*SQLCheck       `hcl:"mysql,block"`
+	Redis      []*RedisCheck     `hcl:"redis,block"`
+	GRPCChecks []*GRPCCheck      `hcl:"grpc,block"`
+	WebSockets []*WebSocketCheck `hcl:"websocket,block"`
+	SMTPChecks []*SMTPCheck      `hcl:"smtp,block"`
+	Banners    []*BannerCheck    `hcl:"banner,block"`
+	UDPChecks  []*UDPCheck       `hcl:"udp,block"`
+	Scenarios  []*ScenarioCheck  `hcl:"scenario,block"`
+}
+
+// Checks returns all checks defined for service.
+func (s *Service) Checks() []Check {
+	checks := make([]Check, 0)
+	for _, h := range s.HTTPChecks {
+		checks = append(checks, h)
+	}
+	for _, t := range s.TCPChecks {
+		checks = append(checks, t)
+	}
+	for _, t := range s.TLSChecks {
+		checks = append(checks, t)
+	}
+	for _, d := range s.DNSChecks {
+		checks = append(checks, d)
+	}
+	for _, h := range s.Heartbeats {
+		checks = append(checks, h)
+	}
+	for _, e := range s.ExecChecks {
+		checks = append(checks, e)
+	}
+	for _, p := range s.Postgres {
+		checks = append(checks, p)
+	}
+	for _, m := range s.MySQL {
+		checks = append(checks, m)
+	}
+	for _, r := range s.Redis {
+		checks = append(checks, r)
+	}
+	for _, g := range s.GRPCChecks {
+		checks = append(checks, g)
+	}
+	for _, w := range s.WebSockets {
+		checks = append(checks, w)
+	}
+	for _, c := range s.SMTPChecks {
+		checks = append(checks, c)
+	}
+	for _, b := range s.Banners {
+		checks = append(checks, b)
+	}
+	for _, u := range s.UDPChecks {
+		checks = append(checks, u)
+	}
+	for _, sc := range s.Scenarios {
+		checks = append(checks, sc)
+	}
+	return checks
+}
+
+// IPVersions returns sorted list of ip versions used by service http checks.
+func (s *Service) IPVersions() []string {
+	seen := map[string]bool{}
+	for _, h := range s.HTTPChecks {
+		for _, v := range h.IPVersions() {
+			seen[v] = true
+		}
+	}
+
+	versions := make([]string, 0, len(seen))
+	for v := range seen {
+		versions = append(versions, v)
+	}
+	sort.Strings(versions)
+	return versions
 }
 
 type ref struct {
-	Monitor *Monitor
-	Service *Service
+	Monitor *Monitor `json:"-"`
+	Service *Service `json:"-"`
+
+	id       string
+	failures failureCounter
+}
+
+func (r *ref) bind(m *Monitor, s *Service, id string) {
+	r.Monitor = m
+	r.Service = s
+	r.id = id
+}
+
+// ID returns check identifier.
+func (r *ref) ID() string {
+	return r.id
+}
+
+// result creates check result prefilled with check references.
+func (r *ref) result(at time.Time) CheckResult {
+	return CheckResult{
+		MonitorID: r.Monitor.ID,
+		ServiceID: r.Service.ID,
+		At:        at,
+	}
 }
@@ -0,0 +1,274 @@
+package deer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxRedisReply limits size of reply read from server.
+	maxRedisReply = 4096
+	// maxRedisDepth limits nesting of array replies.
+	maxRedisDepth = 8
+)
+
+// RedisCheck defines redis connectivity check.
+type RedisCheck struct {
+	ref
+
+	// body
+	IntervalSec     float64  `hcl:"interval"`
+	TimeoutSec      uint64   `hcl:"timeout"`
+	JitterSec       uint64   `hcl:"jitter,optional"`
+	Retries         uint64   `hcl:"retries,optional"`
+	RetryDelaySec   uint64   `hcl:"retry_delay,optional"`
+	ConfirmFailures uint64   `hcl:"confirm_failures,optional"`
+	Addr            string   `hcl:"addr"`
+	PasswordEnv     string   `hcl:"password_env,optional"`
+	PasswordFile    string   `hcl:"password_file,optional"`
+	Command         []string `hcl:"command,optional"`
+	Expectations    []Expect `hcl:"expect,block"`
+
+	password string
+}
+
+// RedisResponse contains the result of the command.
+type RedisResponse struct {
+	Err    error
+	Result string
+	Trace  Trace
+}
+
+// Validate ensures correct values are set for redis check.
+func (r *RedisCheck) Validate() error {
+	if err := validateSchedule(r.IntervalSec, r.TimeoutSec, r.JitterSec, r.retryPolicy()); err != nil {
+		return err
+	}
+
+	if len(r.Addr) == 0 {
+		return fmt.Errorf("Addr cannot be empty")
+	}
+
+	r.password = ""
+	if r.PasswordEnv != "" || r.PasswordFile != "" {
+		password, err := readSecret(r.PasswordEnv, r.PasswordFile)
+		if err != nil {
+			return fmt.Errorf("Redis password: %v", err)
+		}
+		r.password = password
+	}
+
+	if len(r.Command) == 0 {
+		r.Command = []string{"PING"}
+	}
+
+	for _, expect := range r.Expectations {
+		switch expect.Subject {
+		case "result":
+			if err := expect.validateMatcher("Result"); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("Invalid expectation subject")
+		}
+	}
+
+	return nil
+}
+
+// Interval returns how often check should be run.
+func (r *RedisCheck) Interval() time.Duration {
+	return seconds(r.IntervalSec)
+}
+
+// Jitter returns maximum random delay added to each run.
+func (r *RedisCheck) Jitter() time.Duration {
+	return time.Duration(r.JitterSec) * time.Second
+}
+
+// target returns addr, from which check ID is derived.
+func (r *RedisCheck) target() string {
+	return r.Addr
+}
+
+// retryPolicy returns how failed probes are retried.
+func (r *RedisCheck) retryPolicy() retryPolicy {
+	return retryPolicy{r.Retries, r.RetryDelaySec, r.ConfirmFailures}
+}
+
+// RunFn returns task function to run check.
+func (r *RedisCheck) RunFn(s Store) func(context.Context) {
+	store := s
+
+	return func(ctx context.Context) {
+		r.ref.run(ctx, store, r.retryPolicy(), "", func() CheckResult {
+			now := time.Now()
+			resp := r.Exec(ctx, time.Duration(r.TimeoutSec)*time.Second)
+
+			result := r.ref.result(now)
+			result.Trace = &resp.Trace
+			result.verdict(r.Verify(resp))
+			if resp.Err == nil {
+				result.Details = &Details{Query: &QueryDetails{Result: resp.Result}}
+			}
+
+			return result
+		})
+	}
+}
+
+// Exec connects to redis, authenticates (if password is set) and runs the command.
+func (r *RedisCheck) Exec(ctx context.Context, timeout time.Duration) *RedisResponse {
+	var resp RedisResponse
+
+	start := time.Now()
+	defer func() {
+		resp.Trace.Total = time.Since(start)
+	}()
+
+	conn, err := dialTCP(ctx, "tcp", r.Addr, timeout, &resp.Trace)
+	if err != nil {
+		resp.Err = err
+		return &resp
+	}
+	defer conn.Close()
+	defer closeOnDone(ctx, conn)()
+
+	if err := conn.SetDeadline(start.Add(timeout)); err != nil {
+		resp.Err = err
+		return &resp
+	}
+	rd := bufio.NewReader(conn)
+
+	if r.password != "" {
+		if _, err := conn.Write(encodeRESP([]string{"AUTH", r.password})); err != nil {
+			resp.Err = err
+			return &resp
+		}
+		if _, err := readRESP(rd); err != nil {
+			resp.Err = fmt.Errorf("Redis auth: %v", err)
+			return &resp
+		}
+	}
+
+	started := time.Now()
+	if _, err := conn.Write(encodeRESP(r.Command)); err != nil {
+		resp.Err = err
+		return &resp
+	}
+	resp.Result, resp.Err = readRESP(rd)
+	resp.Trace.ServerProcessing = time.Since(started)
+
+	return &resp
+}
+
+// Check verifies if check is valid or not.
+func (r *RedisCheck) Check(resp *RedisResponse) bool {
+	return r.Verify(resp) == nil
+}
+
+// Verify returns the reason why check is not valid or nil when it passes.
+func (r *RedisCheck) Verify(resp *RedisResponse) error {
+	if resp.Err != nil {
+		return resp.Err
+	}
+
+	for _, expect := range r.Expectations {
+		switch expect.Subject {
+		case "result":
+			if err := expect.verifyString("Result", resp.Result); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// encodeRESP encodes command as array of bulk strings.
+func encodeRESP(args []string) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	return b.Bytes()
+}
+
+// readRESP reads single reply. Arrays are flattened and joined with new lines.
+// Replies larger than maxRedisReply or nested deeper than maxRedisDepth are rejected.
+func readRESP(rd *bufio.Reader) (string, error) {
+	budget := maxRedisReply
+	return readReply(rd, 0, &budget)
+}
+
+// readReply reads single reply, charging its length to budget shared by the whole reply.
+func readReply(rd *bufio.Reader, depth int, budget *int) (string, error) {
+	// lines longer than reader buffer fail with bufio.ErrBufferFull
+	raw, err := rd.ReadSlice('\n')
+	if err != nil {
+		return "", err
+	}
+	line := strings.TrimRight(string(raw), "\r\n")
+	if len(line) == 0 {
+		return "", fmt.Errorf("Empty redis reply")
+	}
+
+	switch line[0] {
+	case '+', ':':
+		return line[1:], nil
+
+	case '-':
+		return "", fmt.Errorf("Redis error: %s", line[1:])
+
+	case '$':
+		n, err := replyLen(line, budget)
+		if err != nil || n < 0 {
+			return "", err
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(rd, buf); err != nil {
+			return "", err
+		}
+		return string(buf[:n]), nil
+
+	case '*':
+		if depth >= maxRedisDepth {
+			return "", fmt.Errorf("Redis reply nested deeper than %d", maxRedisDepth)
+		}
+		n, err := replyLen(line, budget)
+		if err != nil || n < 0 {
+			return "", err
+		}
+		items := make([]string, n)
+		for i := range items {
+			if items[i], err = readReply(rd, depth+1, budget); err != nil {
+				return "", err
+			}
+		}
+		return strings.Join(items, "\n"), nil
+	}
+
+	return "", fmt.Errorf("Invalid redis reply: %q", line)
+}
+
+// replyLen parses bulk or array length and charges it to budget.
+// Negative length is null reply and is returned as is.
+func replyLen(line string, budget *int) (int, error) {
+	n, err := strconv.Atoi(line[1:])
+	if err != nil {
+		return 0, err
+	}
+	if n > *budget {
+		return 0, fmt.Errorf("Redis reply exceeds %d bytes", maxRedisReply)
+	}
+	if n > 0 {
+		*budget -= n
+	}
+	return n, nil
+}
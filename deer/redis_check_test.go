@@ -0,0 +1,168 @@
+package deer
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/franela/goblin"
+)
+
+// redisStandIn is a local redis server understanding AUTH, PING and GET commands.
+func redisStandIn(password string) (net.Listener, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				rd := bufio.NewReader(conn)
+				authorized := password == ""
+				for {
+					cmd, err := readRESP(rd)
+					if err != nil {
+						return
+					}
+					args := strings.Split(cmd, "\n")
+
+					switch {
+					case args[0] == "AUTH" && args[1] == password:
+						authorized = true
+						conn.Write([]byte("+OK\r\n"))
+					case args[0] == "AUTH":
+						conn.Write([]byte("-WRONGPASS invalid password\r\n"))
+					case !authorized:
+						conn.Write([]byte("-NOAUTH Authentication required.\r\n"))
+					case args[0] == "PING":
+						conn.Write([]byte("+PONG\r\n"))
+					case args[0] == "GET":
+						conn.Write([]byte("$2\r\n42\r\n"))
+					default:
+						conn.Write([]byte("-ERR unknown command\r\n"))
+					}
+				}
+			}(conn)
+		}
+	}()
+
+	return ln, nil
+}
+
+// rawRedis is a local server replying with reply to any command.
+func rawRedis(reply string) (net.Listener, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				if _, err := readRESP(bufio.NewReader(conn)); err == nil {
+					conn.Write([]byte(reply))
+				}
+			}(conn)
+		}
+	}()
+
+	return ln, nil
+}
+
+func TestRedisCheck(t *testing.T) {
+	g := goblin.Goblin(t)
+	g.Describe("RedisCheck", func() {
+		var ln net.Listener
+
+		g.Before(func() {
+			var err error
+			ln, err = redisStandIn("s3cr3t")
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+
+		g.After(func() {
+			ln.Close()
+		})
+
+		g.It("Authenticates and pings", func() {
+			check := &RedisCheck{
+				Addr:         ln.Addr().String(),
+				Command:      []string{"PING"},
+				password:     "s3cr3t",
+				Expectations: []Expect{{Subject: "result", Equals: "PONG"}},
+			}
+			resp := check.Exec(context.Background(), time.Second)
+
+			g.Assert(check.Verify(resp)).IsNil()
+			g.Assert(resp.Result).Equal("PONG")
+		})
+
+		g.It("Runs configured command", func() {
+			check := &RedisCheck{
+				Addr:         ln.Addr().String(),
+				Command:      []string{"GET", "queue:depth"},
+				password:     "s3cr3t",
+				Expectations: []Expect{{Subject: "result", Matches: `^\d+$`}},
+			}
+
+			g.Assert(check.Check(check.Exec(context.Background(), time.Second))).IsTrue()
+		})
+
+		g.It("Fails on redis error", func() {
+			check := &RedisCheck{
+				Addr:    ln.Addr().String(),
+				Command: []string{"PING"},
+			}
+
+			g.Assert(check.Verify(check.Exec(context.Background(), time.Second)).Error()).Equal("Redis error: NOAUTH Authentication required.")
+		})
+
+		g.It("Fails on oversized reply", func() {
+			for reply, reason := range map[string]string{
+				"$9223372036854775807\r\n": "Redis reply exceeds 4096 bytes",
+				"*1099511627776\r\n":       "Redis reply exceeds 4096 bytes",
+				"*2\r\n$4000\r\n" + strings.Repeat("x", 4000) + "\r\n$200\r\n": "Redis reply exceeds 4096 bytes",
+				strings.Repeat("*1\r\n", 9) + "+OK\r\n":                        "Redis reply nested deeper than 8",
+				"+" + strings.Repeat("x", 5000) + "\r\n":                       "bufio: buffer full",
+			} {
+				raw, err := rawRedis(reply)
+				if err != nil {
+					t.Fatal(err)
+				}
+				check := &RedisCheck{Addr: raw.Addr().String(), Command: []string{"PING"}}
+
+				g.Assert(check.Verify(check.Exec(context.Background(), time.Second)).Error()).Equal(reason)
+				raw.Close()
+			}
+		})
+
+		g.It("Reads nested array reply", func() {
+			raw, err := rawRedis("*2\r\n*1\r\n$3\r\none\r\n:2\r\n")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer raw.Close()
+			check := &RedisCheck{Addr: raw.Addr().String(), Command: []string{"LRANGE", "q", "0", "-1"}}
+			resp := check.Exec(context.Background(), time.Second)
+
+			g.Assert(resp.Err).IsNil()
+			g.Assert(resp.Result).Equal("one\n2")
+		})
+	})
+}
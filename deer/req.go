@@ -1,6 +1,7 @@
 package deer
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"fmt"
@@ -9,18 +10,49 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptrace"
-	"strings"
 	"time"
 )
 
+// DefaultUserAgent is sent with every request unless overridden.
+const DefaultUserAgent = "OhDeer/0.0.1"
+
+// DefaultMaxBodyBytes limits how much of response body is read unless overridden.
+const DefaultMaxBodyBytes = 10 << 20
+
 // Request is a http request with tracing.
-type Request struct{}
+type Request struct {
+	Method          string
+	Header          http.Header
+	Body            []byte
+	TLSConfig       *tls.Config
+	FollowRedirects bool
+	MaxRedirects    int
+	// Network is one of tcp4 (default) or tcp6.
+	Network string
+	// Jar is shared between requests of the same scenario.
+	Jar http.CookieJar
+	// MaxBodyBytes caps the body read, larger responses fail with body truncated to the cap.
+	MaxBodyBytes int64
+}
 
 // Response contains the result of the request check.
 type Response struct {
-	Err   error
-	Resp  *http.Response
-	Trace Trace
+	Err       error
+	Resp      *http.Response
+	Body      []byte
+	Redirects []Redirect
+	Trace     Trace
+
+	// content change detection
+	BodyHash string
+	Changed  bool
+}
+
+// Redirect describes a single redirect hop.
+type Redirect struct {
+	URL        string        `json:"url"`
+	StatusCode int           `json:"status_code"`
+	Duration   time.Duration `json:"duration"`
 }
 
 // Trace contains details about the request duration.
@@ -31,6 +63,10 @@ type Trace struct {
 	ServerProcessing time.Duration `json:"server_processing"`
 	ContentTransfer  time.Duration `json:"content_transfer"`
 	Total            time.Duration `json:"total"`
+
+	// websocket specific
+	WebSocketHandshake time.Duration `json:"websocket_handshake,omitempty"`
+	RoundTrip          time.Duration `json:"round_trip,omitempty"`
 }
 
 const (
@@ -47,19 +83,38 @@ const (
 )
 
 // Get executes GET request.
-func (*Request) Get(address string, timeout time.Duration) *Response {
+func (r *Request) Get(ctx context.Context, address string, timeout time.Duration) *Response {
+	r.Method = http.MethodGet
+	return r.Do(ctx, address, timeout)
+}
+
+// Do executes request with configured method, headers and body.
+// Request is aborted when ctx is cancelled.
+func (r *Request) Do(ctx context.Context, address string, timeout time.Duration) *Response {
 	var (
 		resp  Response
 		times [10]time.Time
 	)
 
-	req, err := http.NewRequest("GET", address, strings.NewReader(""))
+	method := r.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	req, err := http.NewRequest(method, address, bytes.NewReader(r.Body))
 	if err != nil {
 		resp.Err = err
 		return &resp
 	}
 	req.Header = http.Header{}
-	req.Header["User-Agent"] = []string{"OhDeer/0.0.1"}
+	for k, v := range r.Header {
+		req.Header[k] = v
+	}
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", DefaultUserAgent)
+	}
+	if host := req.Header.Get("Host"); host != "" {
+		req.Host = host
+	}
 
 	// Inspired by:
 	// https://github.com/davecheney/httpstat/blob/master/main.go
@@ -101,15 +156,18 @@ func (*Request) Get(address string, timeout time.Duration) *Response {
 			times[tTLSDone] = time.Now()
 		},
 	}
-	req = req.WithContext(httptrace.WithClientTrace(context.Background(), trace))
+	req = req.WithContext(httptrace.WithClientTrace(ctx, trace))
 
-	// TODO: allow to parametrize network to tcp6
+	network := r.Network
+	if network == "" {
+		network = "tcp4"
+	}
 	dialCtx := func(ctx context.Context, _, addr string) (net.Conn, error) {
 		return (&net.Dialer{
 			Timeout:   timeout,
 			KeepAlive: timeout,
 			DualStack: false,
-		}).DialContext(ctx, "tcp4", addr)
+		}).DialContext(ctx, network, addr)
 	}
 
 	tr := &http.Transport{
@@ -119,23 +177,51 @@ func (*Request) Get(address string, timeout time.Duration) *Response {
 		TLSHandshakeTimeout:   timeout,
 		ExpectContinueTimeout: timeout,
 		DialContext:           dialCtx,
+		TLSClientConfig:       r.TLSConfig,
 	}
 
+	var hopStart time.Time
 	client := &http.Client{
 		Timeout:   timeout,
 		Transport: tr,
+		Jar:       r.Jar,
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			return http.ErrUseLastResponse // no redirects
+			if !r.FollowRedirects {
+				return http.ErrUseLastResponse // no redirects
+			}
+
+			now := time.Now()
+			resp.Redirects = append(resp.Redirects, Redirect{
+				URL:        via[len(via)-1].URL.String(),
+				StatusCode: req.Response.StatusCode,
+				Duration:   now.Sub(hopStart),
+			})
+			hopStart = now
+
+			if len(via) > r.MaxRedirects {
+				return fmt.Errorf("Stopped after %d redirects", r.MaxRedirects)
+			}
+			return nil
 		},
 	}
 
 	times[tReqStart] = time.Now()
+	hopStart = times[tReqStart]
 	resp.Resp, resp.Err = client.Do(req)
 	if traceErr != nil {
 		resp.Err = traceErr
 	}
 	if resp.Err == nil {
-		io.Copy(ioutil.Discard, resp.Resp.Body) // simulate body read
+		max := r.MaxBodyBytes
+		if max <= 0 {
+			max = DefaultMaxBodyBytes
+		}
+		resp.Body, resp.Err = ioutil.ReadAll(io.LimitReader(resp.Resp.Body, max+1))
+		if int64(len(resp.Body)) > max {
+			resp.Body = resp.Body[:max]
+			resp.Err = fmt.Errorf("Body exceeds %d bytes", max)
+		}
+		resp.Resp.Body.Close()
 	}
 	times[tReqDone] = time.Now()
 
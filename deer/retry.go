@@ -0,0 +1,104 @@
+package deer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// retryPolicy defines how failed probes are handled before failure is saved.
+type retryPolicy struct {
+	// Retries is number of additional attempts made within the same run.
+	Retries       uint64
+	RetryDelaySec uint64
+	// ConfirmFailures is number of consecutive failed runs needed before failure is saved,
+	// failures before that are saved as degraded.
+	ConfirmFailures uint64
+}
+
+// validate ensures all attempts fit in the interval, otherwise retries would turn into skipped runs.
+func (p retryPolicy) validate(interval, timeout time.Duration) error {
+	if p.RetryDelaySec > 0 && p.Retries == 0 {
+		return fmt.Errorf("Retry delay requires retries")
+	}
+
+	delay := time.Duration(p.RetryDelaySec) * time.Second
+	if worst := time.Duration(p.Retries+1)*timeout + time.Duration(p.Retries)*delay; p.Retries > 0 && worst > interval {
+		return fmt.Errorf("Retries with timeout and delay (%s) must fit in the interval", worst)
+	}
+
+	return nil
+}
+
+// permanentError is failure which is not retried nor waits for confirmation (e.g. content change).
+type permanentError struct {
+	error
+}
+
+// run probes the check until it passes or retries are exhausted and saves result of the last attempt.
+// Failures are saved as degraded until they occur in the configured number of consecutive runs.
+// Key separates consecutive failures of probes run within the same check, e.g. per ip version.
+func (r *ref) run(ctx context.Context, s Store, p retryPolicy, key string, probe func() CheckResult) {
+	var (
+		result    CheckResult
+		attempts  uint64
+		permanent bool
+	)
+
+	for {
+		attempts++
+		result = probe()
+		_, permanent = result.Error.(*permanentError)
+		if result.Success || permanent || attempts > p.Retries {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Duration(p.RetryDelaySec) * time.Second):
+		}
+	}
+
+	if !permanent {
+		result.verdict(r.failures.confirm(key, p.ConfirmFailures, result.Error))
+	}
+	if p.Retries > 0 {
+		if result.Details == nil {
+			result.Details = &Details{}
+		}
+		result.Details.Attempts = int(attempts)
+	}
+
+	save(ctx, s, &result)
+}
+
+// failureCounter counts consecutive failed runs per key.
+type failureCounter struct {
+	mu     sync.Mutex
+	counts map[string]uint64
+}
+
+// confirm returns err as is once it occurred in n consecutive runs.
+// Until then failure is reported as degraded, so it is still visible but does not count as downtime.
+// Any passed run resets the counter.
+func (f *failureCounter) confirm(key string, n uint64, err error) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, degraded := err.(*DegradedError); err == nil || degraded {
+		delete(f.counts, key)
+		return err
+	}
+
+	if f.counts == nil {
+		f.counts = map[string]uint64{}
+	}
+	f.counts[key]++
+
+	if count := f.counts[key]; count < n {
+		return &DegradedError{Reason: fmt.Errorf("Unconfirmed failure %d/%d: %v", count, n, err)}
+	}
+	return err
+}
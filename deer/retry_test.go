@@ -0,0 +1,101 @@
+package deer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/franela/goblin"
+)
+
+func TestRetry(t *testing.T) {
+	g := goblin.Goblin(t)
+	g.Describe("Retry", func() {
+		var (
+			r     *ref
+			store *testStore
+			calls int
+		)
+
+		g.BeforeEach(func() {
+			r = &ref{}
+			r.bind(&Monitor{ID: "aws"}, &Service{ID: "api"}, "aws/api/tcp/0")
+			store = &testStore{}
+			calls = 0
+		})
+
+		// probe fails with err on the first failures calls and passes afterwards
+		probe := func(failures int, err error) func() CheckResult {
+			return func() CheckResult {
+				calls++
+				result := r.result(time.Now())
+				result.Trace = &Trace{}
+				if calls <= failures {
+					result.verdict(err)
+				} else {
+					result.verdict(nil)
+				}
+				return result
+			}
+		}
+
+		g.It("Saves only the last attempt", func() {
+			r.run(context.Background(), store, retryPolicy{Retries: 2}, "", probe(1, fmt.Errorf("Connection refused")))
+
+			g.Assert(calls).Equal(2)
+			g.Assert(len(store.Results())).Equal(1)
+			g.Assert(store.Results()[0].Success).IsTrue()
+			g.Assert(store.Results()[0].Details.Attempts).Equal(2)
+		})
+
+		g.It("Does not record attempts without retries", func() {
+			r.run(context.Background(), store, retryPolicy{}, "", probe(1, fmt.Errorf("Connection refused")))
+
+			g.Assert(calls).Equal(1)
+			g.Assert(store.Results()[0].Success).IsFalse()
+			g.Assert(store.Results()[0].Details == nil).IsTrue()
+		})
+
+		g.It("Confirms failures per key", func() {
+			policy := retryPolicy{ConfirmFailures: 2}
+			failing := probe(10, fmt.Errorf("Connection refused"))
+
+			r.run(context.Background(), store, policy, "4", failing)
+			r.run(context.Background(), store, policy, "6", failing)
+			r.run(context.Background(), store, policy, "4", failing)
+			results := store.Results()
+
+			g.Assert(results[0].Degraded).IsTrue()
+			g.Assert(results[1].Degraded).IsTrue()
+			g.Assert(results[2].Success).IsFalse()
+		})
+
+		g.It("Neither retries nor confirms permanent failure", func() {
+			err := &permanentError{fmt.Errorf("Body changed")}
+			r.run(context.Background(), store, retryPolicy{Retries: 2, ConfirmFailures: 3}, "", probe(10, err))
+
+			g.Assert(calls).Equal(1)
+			g.Assert(store.Results()[0].Success).IsFalse()
+			g.Assert(store.Results()[0].Error.Error()).Equal("Body changed")
+		})
+
+		g.It("Stops retrying when context is cancelled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			r.run(ctx, store, retryPolicy{Retries: 2, RetryDelaySec: 60}, "", probe(10, fmt.Errorf("Connection refused")))
+
+			g.Assert(calls).Equal(1)
+			g.Assert(len(store.Results())).Equal(0)
+		})
+
+		g.It("Requires attempts to fit in the interval", func() {
+			g.Assert(retryPolicy{Retries: 2, RetryDelaySec: 1}.validate(time.Minute, 10*time.Second)).IsNil()
+			g.Assert(retryPolicy{}.validate(5*time.Second, 10*time.Second)).IsNil()
+			g.Assert(retryPolicy{Retries: 2, RetryDelaySec: 5}.validate(30*time.Second, 10*time.Second).Error()).
+				Equal("Retries with timeout and delay (40s) must fit in the interval")
+			g.Assert(retryPolicy{RetryDelaySec: 1}.validate(time.Minute, time.Second).Error()).
+				Equal("Retry delay requires retries")
+		})
+	})
+}
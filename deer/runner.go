@@ -2,50 +2,211 @@ package deer
 
 import (
 	"context"
-
-	"github.com/jasonlvhit/gocron"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
 )
 
+// ErrHeartbeatNotFound is returned when ping does not match any heartbeat check.
+var ErrHeartbeatNotFound = errors.New("Heartbeat not found")
+
+// pingTimeout limits how long saving heartbeat can take.
+const pingTimeout = 5 * time.Second
+
 // Runner is responsible for scheduling jobs.
 type Runner struct {
+	store     Store
+	scheduler *Scheduler
+	limiter   *limiter
+
+	mu     sync.RWMutex
 	cfg    *Config
-	cronCh chan bool
-	store  Store
+	checks map[string]scheduledCheck
 }
 
-// NewRunner creates runner instance.
+type scheduledCheck struct {
+	check       Check
+	monitorID   string
+	fingerprint string
+}
+
+// ReloadStats summarizes changes applied on reload.
+type ReloadStats struct {
+	Added   int
+	Removed int
+	Updated int
+}
+
+// NewRunner creates runner instance with all config checks scheduled.
 func NewRunner(cfg *Config, store Store) *Runner {
-	return &Runner{
-		cfg:   cfg,
-		store: store,
+	r := Runner{
+		store:     store,
+		scheduler: NewScheduler(nil),
+		limiter:   newLimiter(cfg, realClock{}),
+		checks:    map[string]scheduledCheck{},
 	}
+	r.Reload(cfg)
+
+	return &r
 }
 
-// Start begins cron jobs.
+// Start runs scheduled checks and blocks until ctx is cancelled or runner is shut down.
 func (r *Runner) Start(ctx context.Context) {
-	for _, m := range r.cfg.Monitors {
+	r.scheduler.Run(ctx)
+}
+
+// Config returns currently applied config.
+func (r *Runner) Config() *Config {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.cfg
+}
+
+// Reload applies new config. Checks are matched by ID and only added, removed
+// and changed ones are rescheduled, unchanged checks keep running with their state (e.g. last heartbeat).
+func (r *Runner) Reload(cfg *Config) ReloadStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var stats ReloadStats
+
+	r.limiter.configure(cfg)
+
+	next := map[string]scheduledCheck{}
+	for _, m := range cfg.Monitors {
 		for _, s := range m.Services {
-			for _, h := range s.HTTPChecks {
-				gocron.Every(h.IntervalSec).Seconds().Do(h.RunFn(r.store))
+			for _, c := range s.Checks() {
+				next[c.ID()] = scheduledCheck{check: c, monitorID: m.ID, fingerprint: fingerprint(c)}
 			}
 		}
 	}
 
-	r.cronCh = gocron.Start()
-LOOP:
-	for {
-		select {
-		case <-r.cronCh:
-			break LOOP
-		case <-ctx.Done():
-			r.cronCh <- true
-			break LOOP
+	for id := range r.checks {
+		if _, ok := next[id]; !ok {
+			r.scheduler.Remove(id)
+			delete(r.checks, id)
+			stats.Removed++
 		}
 	}
+
+	for id, sc := range next {
+		current, ok := r.checks[id]
+		switch {
+		case ok && sc.fingerprint != "" && current.fingerprint == sc.fingerprint:
+			continue
+
+		case ok:
+			r.scheduler.Remove(id)
+			stats.Updated++
+
+		default:
+			stats.Added++
+		}
+
+		r.checks[id] = sc
+		r.scheduler.Add(Job{
+			ID:       id,
+			Interval: sc.check.Interval(),
+			Jitter:   sc.check.Jitter(),
+			Fn:       r.limiter.wrap(sc.monitorID, sc.check.RunFn(r.store)),
+		})
+	}
+
+	r.cfg = cfg
+
+	return stats
 }
 
-// Shutdown stops all the tasks and closes scheduler.
-func (r *Runner) Shutdown(ctx context.Context) {
-	r.cronCh <- true
-	gocron.Clear()
+// Ping records heartbeat for service when token matches any of its heartbeat checks.
+func (r *Runner) Ping(ctx context.Context, monitorID, serviceID, token string) error {
+	h := r.heartbeat(monitorID, serviceID, token)
+	if h == nil {
+		return ErrHeartbeatNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+	h.Ping(ctx, r.store, time.Now())
+
+	return nil
+}
+
+// heartbeat returns heartbeat check of the service authorized by token.
+// Lock is not held while the ping is saved, so that slow store does not block reloads.
+func (r *Runner) heartbeat(monitorID, serviceID, token string) *HeartbeatCheck {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, sc := range r.checks {
+		h, ok := sc.check.(*HeartbeatCheck)
+		if !ok || sc.monitorID != monitorID || h.Service.ID != serviceID {
+			continue
+		}
+		if h.Authorize(token) {
+			return h
+		}
+	}
+
+	return nil
+}
+
+// Stats returns probe execution statistics.
+func (r *Runner) Stats() RunnerStats {
+	stats := r.limiter.Stats()
+	stats.Skipped = r.scheduler.Skipped()
+	return stats
+}
+
+// Shutdown stops all the tasks, cancels in-flight probes and waits for them to return.
+func (r *Runner) Shutdown(ctx context.Context) error {
+	return r.scheduler.Stop(ctx)
+}
+
+// fingerprint returns digest of check configuration used to detect changes on reload.
+// Values read from env variables and files are included, so that e.g. rotated tokens are applied.
+func fingerprint(c Check) string {
+	b, err := json.Marshal(struct {
+		Check    Check
+		Resolved []string
+	}{c, resolved(c)})
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// resolved returns values which checks read from env variables and files on validation.
+func resolved(c Check) []string {
+	switch c := c.(type) {
+	case *HeartbeatCheck:
+		return []string{c.token}
+
+	case *SQLCheck:
+		return []string{c.dsn}
+
+	case *RedisCheck:
+		return []string{c.password}
+
+	case *HTTPCheck:
+		values := []string{c.authorization, string(c.body)}
+		if c.tlsConfig != nil {
+			for _, cert := range c.tlsConfig.Certificates {
+				for _, der := range cert.Certificate {
+					values = append(values, string(der))
+				}
+			}
+			if c.tlsConfig.RootCAs != nil {
+				for _, subject := range c.tlsConfig.RootCAs.Subjects() {
+					values = append(values, string(subject))
+				}
+			}
+		}
+		return values
+	}
+	return nil
 }
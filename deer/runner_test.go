@@ -0,0 +1,214 @@
+package deer
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/franela/goblin"
+)
+
+// blockingStore blocks saving until released.
+type blockingStore struct {
+	testStore
+	saving  chan bool
+	release chan bool
+}
+
+func (s *blockingStore) Save(ctx context.Context, result *CheckResult) {
+	s.saving <- true
+	<-s.release
+	s.testStore.Save(ctx, result)
+}
+
+func TestRunner(t *testing.T) {
+	g := goblin.Goblin(t)
+	g.Describe("Runner", func() {
+		parse := func(src string) *Config {
+			os.Setenv("OHDEER_TEST_HEARTBEAT", "s3cr3t")
+			defer os.Unsetenv("OHDEER_TEST_HEARTBEAT")
+
+			cfg, err := ParseConfig("runner.hcl", []byte(src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return cfg
+		}
+
+		initial := `
+		monitor "jobs" {
+			name = "Jobs"
+			service "backup" {
+				name = "Backup"
+				heartbeat {
+					period    = 60
+					token_env = "OHDEER_TEST_HEARTBEAT"
+				}
+			}
+			service "api" {
+				name = "API"
+				tcp {
+					addr     = "localhost:1"
+					interval = 60
+					timeout  = 1
+				}
+				tcp {
+					addr     = "localhost:2"
+					interval = 60
+					timeout  = 1
+				}
+			}
+		}
+		`
+
+		g.It("Reschedules only changed checks", func() {
+			runner := NewRunner(parse(initial), &testStore{})
+
+			stats := runner.Reload(parse(`
+			monitor "jobs" {
+				name = "Jobs"
+				service "backup" {
+					name = "Backup"
+					heartbeat {
+						period    = 60
+						token_env = "OHDEER_TEST_HEARTBEAT"
+					}
+				}
+				service "api" {
+					name = "API"
+					tcp {
+						addr     = "localhost:1"
+						interval = 30
+						timeout  = 1
+					}
+				}
+				service "db" {
+					name = "DB"
+					tcp {
+						addr     = "localhost:3"
+						interval = 60
+						timeout  = 1
+					}
+				}
+			}
+			`))
+
+			g.Assert(stats).Equal(ReloadStats{Added: 1, Removed: 1, Updated: 1})
+			g.Assert(len(runner.Config().Monitors[0].Services)).Equal(3)
+		})
+
+		g.It("Keeps checks when sibling is inserted before them", func() {
+			runner := NewRunner(parse(initial), &testStore{})
+
+			stats := runner.Reload(parse(`
+			monitor "jobs" {
+				name = "Jobs"
+				service "backup" {
+					name = "Backup"
+					heartbeat {
+						period    = 60
+						token_env = "OHDEER_TEST_HEARTBEAT"
+					}
+				}
+				service "api" {
+					name = "API"
+					tcp {
+						addr     = "localhost:3"
+						interval = 60
+						timeout  = 1
+					}
+					tcp {
+						addr     = "localhost:1"
+						interval = 60
+						timeout  = 1
+					}
+					tcp {
+						addr     = "localhost:2"
+						interval = 60
+						timeout  = 1
+					}
+				}
+			}
+			`))
+
+			g.Assert(stats).Equal(ReloadStats{Added: 1})
+		})
+
+		g.It("Keeps state of unchanged checks", func() {
+			store := &testStore{}
+			cfg := parse(initial)
+			runner := NewRunner(cfg, store)
+			g.Assert(runner.Ping(context.Background(), "jobs", "backup", "s3cr3t")).IsNil()
+			id := cfg.Monitors[0].Services[0].Heartbeats[0].ID()
+			before := runner.checks[id].check
+
+			stats := runner.Reload(parse(initial))
+
+			g.Assert(stats).Equal(ReloadStats{})
+			g.Assert(runner.checks[id].check == before).IsTrue()
+			g.Assert(runner.Ping(context.Background(), "jobs", "backup", "s3cr3t")).IsNil()
+		})
+
+		g.It("Applies rotated heartbeat token", func() {
+			runner := NewRunner(parse(initial), &testStore{})
+
+			os.Setenv("OHDEER_TEST_HEARTBEAT", "n3w")
+			cfg, err := ParseConfig("runner.hcl", []byte(initial))
+			os.Unsetenv("OHDEER_TEST_HEARTBEAT")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			stats := runner.Reload(cfg)
+
+			g.Assert(stats).Equal(ReloadStats{Updated: 1})
+			g.Assert(runner.Ping(context.Background(), "jobs", "backup", "n3w")).IsNil()
+			g.Assert(runner.Ping(context.Background(), "jobs", "backup", "s3cr3t")).Equal(ErrHeartbeatNotFound)
+		})
+
+		g.It("Does not block reload while ping is saved", func() {
+			store := &blockingStore{saving: make(chan bool), release: make(chan bool)}
+			runner := NewRunner(parse(initial), store)
+
+			pinged := make(chan error)
+			go func() {
+				pinged <- runner.Ping(context.Background(), "jobs", "backup", "s3cr3t")
+			}()
+			<-store.saving
+
+			runner.Reload(parse(initial))
+			close(store.release)
+			g.Assert(<-pinged).IsNil()
+		})
+
+		g.It("Stops pinging removed heartbeat", func() {
+			runner := NewRunner(parse(initial), &testStore{})
+
+			runner.Reload(parse(`
+			monitor "jobs" {
+				name = "Jobs"
+			}
+			`))
+
+			g.Assert(runner.Ping(context.Background(), "jobs", "backup", "s3cr3t")).Equal(ErrHeartbeatNotFound)
+		})
+
+		g.It("Stops removed jobs while running", func() {
+			runner := NewRunner(parse(initial), &testStore{})
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go runner.Start(ctx)
+
+			runner.Reload(parse(`
+			monitor "jobs" {
+				name = "Jobs"
+			}
+			`))
+
+			shutdownCtx, done := context.WithTimeout(context.Background(), time.Second)
+			defer done()
+			g.Assert(runner.Shutdown(shutdownCtx)).IsNil()
+		})
+	})
+}
@@ -0,0 +1,317 @@
+package deer
+
+import (
+	"context"
+	"fmt"
+	"net/http/cookiejar"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ScenarioCheck defines multi-step http transaction type check.
+// Steps are run in order and share cookie jar and extracted variables.
+type ScenarioCheck struct {
+	ref
+
+	// body
+	IntervalSec     float64           `hcl:"interval"`
+	TimeoutSec      uint64            `hcl:"timeout"`
+	JitterSec       uint64            `hcl:"jitter,optional"`
+	Retries         uint64            `hcl:"retries,optional"`
+	RetryDelaySec   uint64            `hcl:"retry_delay,optional"`
+	ConfirmFailures uint64            `hcl:"confirm_failures,optional"`
+	Variables       map[string]string `hcl:"variables,optional"`
+	Steps           []*ScenarioStep   `hcl:"step,block"`
+}
+
+// ScenarioStep defines single http request of the scenario.
+// Addr, header values and body can reference variables with {{name}} syntax.
+type ScenarioStep struct {
+	// label
+	Name string `hcl:"name,label"`
+	// body
+	Addr            string            `hcl:"addr"`
+	Method          string            `hcl:"method,optional"`
+	Headers         map[string]string `hcl:"headers,optional"`
+	Body            string            `hcl:"body,optional"`
+	FollowRedirects bool              `hcl:"follow_redirects,optional"`
+	Extracts        []Extract         `hcl:"extract,block"`
+	Expectations    []Expect          `hcl:"expect,block"`
+
+	http *HTTPCheck
+}
+
+// Extract defines how to capture variable from step response.
+// Exactly one of json path, header name or regex must be set.
+// For regex the first capture group is used (or whole match when there is none).
+type Extract struct {
+	// label
+	Variable string `hcl:"variable,label"`
+	// body
+	JSON   string `hcl:"json,optional"`
+	Header string `hcl:"header,optional"`
+	Regex  string `hcl:"regex,optional"`
+}
+
+// ScenarioResponse contains the result of the scenario check.
+type ScenarioResponse struct {
+	Err   error
+	Steps []StepDetails
+	Trace Trace
+	// Last is the response of the last executed step.
+	Last *Response
+}
+
+// StepDetails contains result of single scenario step.
+type StepDetails struct {
+	Name       string `json:"name"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Trace      Trace  `json:"trace"`
+	Error      string `json:"error,omitempty"`
+}
+
+var scenarioVariable = regexp.MustCompile(`\{\{\s*(\w+)\s*\}\}`)
+
+// Validate ensures correct values are set for scenario check.
+// Variables must be defined or extracted by one of previous steps before they are used.
+func (c *ScenarioCheck) Validate() error {
+	if err := validateSchedule(c.IntervalSec, c.TimeoutSec, c.JitterSec, c.retryPolicy()); err != nil {
+		return err
+	}
+
+	if len(c.Steps) == 0 {
+		return fmt.Errorf("At least one step for scenario check is required")
+	}
+
+	defined := map[string]bool{}
+	for name := range c.Variables {
+		defined[name] = true
+	}
+
+	for _, step := range c.Steps {
+		step.http = &HTTPCheck{
+			IntervalSec:     c.IntervalSec,
+			TimeoutSec:      c.TimeoutSec,
+			Addr:            step.Addr,
+			Method:          step.Method,
+			Headers:         step.Headers,
+			Body:            step.Body,
+			FollowRedirects: step.FollowRedirects,
+			Expectations:    step.Expectations,
+		}
+		if err := step.http.Validate(); err != nil {
+			return fmt.Errorf("Step %s: %v", step.Name, err)
+		}
+
+		used := []string{step.Addr, step.Body}
+		for _, v := range step.Headers {
+			used = append(used, v)
+		}
+		for _, s := range used {
+			for _, m := range scenarioVariable.FindAllStringSubmatch(s, -1) {
+				if !defined[m[1]] {
+					return fmt.Errorf("Step %s: undefined variable %s", step.Name, m[1])
+				}
+			}
+		}
+
+		for _, extract := range step.Extracts {
+			if err := extract.validate(); err != nil {
+				return fmt.Errorf("Step %s: %v", step.Name, err)
+			}
+			defined[extract.Variable] = true
+		}
+	}
+
+	return nil
+}
+
+// Interval returns how often check should be run.
+func (c *ScenarioCheck) Interval() time.Duration {
+	return seconds(c.IntervalSec)
+}
+
+// Jitter returns maximum random delay added to each run.
+func (c *ScenarioCheck) Jitter() time.Duration {
+	return time.Duration(c.JitterSec) * time.Second
+}
+
+// target returns first step addr, from which check ID is derived.
+func (c *ScenarioCheck) target() string {
+	if len(c.Steps) == 0 {
+		return ""
+	}
+	return c.Steps[0].Addr
+}
+
+// retryPolicy returns how failed probes are retried.
+func (c *ScenarioCheck) retryPolicy() retryPolicy {
+	return retryPolicy{c.Retries, c.RetryDelaySec, c.ConfirmFailures}
+}
+
+// RunFn returns task function to run check.
+func (c *ScenarioCheck) RunFn(s Store) func(context.Context) {
+	store := s
+
+	return func(ctx context.Context) {
+		c.ref.run(ctx, store, c.retryPolicy(), "", func() CheckResult {
+			now := time.Now()
+			resp := c.Run(ctx, time.Duration(c.TimeoutSec)*time.Second)
+
+			result := c.ref.result(now)
+			result.Trace = &resp.Trace
+			result.verdict(resp.Err)
+			if resp.Last != nil && resp.Last.Resp != nil {
+				result.StatusCode = resp.Last.Resp.StatusCode
+			}
+			result.Details = &Details{Steps: resp.Steps}
+
+			return result
+		})
+	}
+}
+
+// Run executes steps in order until the first failure.
+// Timeout applies to the whole scenario, not to each step.
+// Trace contains phases summed across all executed steps.
+func (c *ScenarioCheck) Run(ctx context.Context, timeout time.Duration) *ScenarioResponse {
+	var resp ScenarioResponse
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		resp.Err = err
+		return &resp
+	}
+
+	vars := map[string]string{}
+	for k, v := range c.Variables {
+		vars[k] = v
+	}
+
+	for _, step := range c.Steps {
+		req := step.http.Request()
+		req.Jar = jar
+		req.Body = []byte(expandVariables(string(req.Body), vars))
+		for k := range req.Header {
+			req.Header.Set(k, expandVariables(req.Header.Get(k), vars))
+		}
+
+		stepResp := req.Do(ctx, expandVariables(step.Addr, vars), timeout)
+		resp.Last = stepResp
+		resp.Trace.add(&stepResp.Trace)
+
+		details := StepDetails{Name: step.Name, Trace: stepResp.Trace}
+		if stepResp.Resp != nil {
+			details.StatusCode = stepResp.Resp.StatusCode
+		}
+
+		err := step.http.Verify(stepResp)
+		_, degraded := err.(*DegradedError)
+		if err == nil || degraded {
+			if extractErr := step.extract(stepResp, vars); extractErr != nil {
+				err, degraded = extractErr, false
+			}
+		}
+		if err != nil {
+			details.Error = err.Error()
+		}
+		resp.Steps = append(resp.Steps, details)
+
+		switch {
+		case degraded && resp.Err == nil:
+			resp.Err = &DegradedError{Reason: fmt.Errorf("Step %s: %v", step.Name, err)}
+
+		case err != nil && !degraded:
+			resp.Err = fmt.Errorf("Step %s: %v", step.Name, err)
+			return &resp
+		}
+	}
+
+	return &resp
+}
+
+// Check verifies if check is valid or not.
+func (c *ScenarioCheck) Check(resp *ScenarioResponse) bool {
+	_, degraded := resp.Err.(*DegradedError)
+	return resp.Err == nil || degraded
+}
+
+// extract captures step variables from the response.
+func (step *ScenarioStep) extract(resp *Response, vars map[string]string) error {
+	for _, extract := range step.Extracts {
+		var (
+			value string
+			err   error
+		)
+
+		switch {
+		case extract.JSON != "":
+			value, err = lookupJSONPath(resp.Body, extract.JSON)
+
+		case extract.Header != "":
+			value = resp.Resp.Header.Get(extract.Header)
+			if value == "" {
+				err = fmt.Errorf("Header %s is missing", extract.Header)
+			}
+
+		case extract.Regex != "":
+			m := regexp.MustCompile(extract.Regex).FindSubmatch(resp.Body)
+			switch {
+			case m == nil:
+				err = fmt.Errorf("Body does not match %q", extract.Regex)
+			case len(m) > 1:
+				value = string(m[1])
+			default:
+				value = string(m[0])
+			}
+		}
+
+		if err != nil {
+			return fmt.Errorf("Cannot extract %s: %v", extract.Variable, err)
+		}
+		vars[extract.Variable] = value
+	}
+
+	return nil
+}
+
+func (e *Extract) validate() error {
+	set := 0
+	for _, s := range []string{e.JSON, e.Header, e.Regex} {
+		if s != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("Extract %s requires exactly one of json, header or regex", e.Variable)
+	}
+	if _, err := regexp.Compile(e.Regex); err != nil {
+		return fmt.Errorf("Invalid extract regexp: %v", err)
+	}
+	return nil
+}
+
+// expandVariables replaces {{name}} references with variable values.
+func expandVariables(s string, vars map[string]string) string {
+	if !strings.Contains(s, "{{") {
+		return s
+	}
+	return scenarioVariable.ReplaceAllStringFunc(s, func(ref string) string {
+		name := scenarioVariable.FindStringSubmatch(ref)[1]
+		return vars[name]
+	})
+}
+
+// add sums trace phases.
+func (t *Trace) add(o *Trace) {
+	t.DNSLookup += o.DNSLookup
+	t.TCPConnection += o.TCPConnection
+	t.TLSHandshake += o.TLSHandshake
+	t.ServerProcessing += o.ServerProcessing
+	t.ContentTransfer += o.ContentTransfer
+	t.Total += o.Total
+}
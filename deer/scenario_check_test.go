@@ -0,0 +1,141 @@
+package deer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/franela/goblin"
+)
+
+func TestScenarioCheck(t *testing.T) {
+	g := goblin.Goblin(t)
+	g.Describe("ScenarioCheck", func() {
+		var srv *httptest.Server
+
+		g.Before(func() {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+				http.SetCookie(w, &http.Cookie{Name: "session", Value: "s3cr3t"})
+				w.Header().Set("X-Request-Id", "req-1")
+				fmt.Fprint(w, `{"token":"abc","user":{"name":"alice"}}`)
+			})
+			mux.HandleFunc("/me", func(w http.ResponseWriter, r *http.Request) {
+				c, err := r.Cookie("session")
+				if err != nil || c.Value != "s3cr3t" || r.Header.Get("Authorization") != "Bearer abc" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				fmt.Fprintf(w, "hello %s from %s", r.URL.Query().Get("name"), r.Header.Get("X-Request-Id"))
+			})
+			mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-time.After(300 * time.Millisecond):
+				case <-r.Context().Done():
+				}
+			})
+			srv = httptest.NewServer(mux)
+		})
+
+		g.After(func() {
+			srv.Close()
+		})
+
+		scenario := func() *ScenarioCheck {
+			return &ScenarioCheck{
+				IntervalSec: 10,
+				TimeoutSec:  1,
+				Variables:   map[string]string{"base": srv.URL},
+				Steps: []*ScenarioStep{
+					{
+						Name:   "login",
+						Addr:   "{{base}}/login",
+						Method: "POST",
+						Extracts: []Extract{
+							{Variable: "token", JSON: "token"},
+							{Variable: "name", Regex: `"name":"(\w+)"`},
+							{Variable: "request", Header: "X-Request-Id"},
+						},
+						Expectations: []Expect{{Subject: "status", Inclusion: []int{200}}},
+					},
+					{
+						Name: "profile",
+						Addr: "{{base}}/me?name={{name}}",
+						Headers: map[string]string{
+							"Authorization": "Bearer {{token}}",
+							"X-Request-Id":  "{{request}}",
+						},
+						Expectations: []Expect{
+							{Subject: "status", Inclusion: []int{200}},
+							{Subject: "body", Equals: "hello alice from req-1"},
+						},
+					},
+				},
+			}
+		}
+
+		g.It("Runs steps sharing cookies and variables", func() {
+			check := scenario()
+			g.Assert(check.Validate()).IsNil()
+			resp := check.Run(context.Background(), time.Second)
+
+			g.Assert(resp.Err).IsNil()
+			g.Assert(len(resp.Steps)).Equal(2)
+			g.Assert(resp.Steps[1].Name).Equal("profile")
+			g.Assert(resp.Steps[1].StatusCode).Equal(200)
+			g.Assert(resp.Trace.Total >= resp.Steps[1].Trace.Total).IsTrue()
+			g.Assert(check.Check(resp)).IsTrue()
+		})
+
+		g.It("Stops at the first failing step", func() {
+			check := scenario()
+			check.Steps[0].Extracts[0].JSON = "access_token"
+			g.Assert(check.Validate()).IsNil()
+			resp := check.Run(context.Background(), time.Second)
+
+			g.Assert(resp.Err.Error()).Equal("Step login: Cannot extract token: Path access_token not found")
+			g.Assert(len(resp.Steps)).Equal(1)
+			g.Assert(check.Check(resp)).IsFalse()
+		})
+
+		g.It("Records failed step expectation", func() {
+			check := scenario()
+			check.Steps[1].Headers["Authorization"] = "Bearer nope"
+			g.Assert(check.Validate()).IsNil()
+			resp := check.Run(context.Background(), time.Second)
+
+			g.Assert(resp.Steps[1].StatusCode).Equal(401)
+			g.Assert(resp.Steps[1].Error).Equal("Status 401 is not in [200]")
+			g.Assert(check.Check(resp)).IsFalse()
+		})
+
+		g.It("Applies timeout to the whole scenario", func() {
+			check := &ScenarioCheck{
+				IntervalSec: 10,
+				TimeoutSec:  1,
+				Steps: []*ScenarioStep{
+					{Name: "first", Addr: srv.URL + "/slow", Expectations: []Expect{{Subject: "status", Inclusion: []int{200}}}},
+					{Name: "second", Addr: srv.URL + "/slow", Expectations: []Expect{{Subject: "status", Inclusion: []int{200}}}},
+				},
+			}
+			g.Assert(check.Validate()).IsNil()
+			// each step alone fits in the timeout, both together do not
+			resp := check.Run(context.Background(), 500*time.Millisecond)
+
+			g.Assert(len(resp.Steps)).Equal(2)
+			g.Assert(resp.Steps[0].Error).Equal("")
+			g.Assert(resp.Steps[1].Error != "").IsTrue()
+			g.Assert(check.Check(resp)).IsFalse()
+		})
+
+		g.It("Fails validation on undefined variable", func() {
+			check := scenario()
+			check.Steps[0].Extracts = nil
+
+			g.Assert(check.Validate().Error()).Equal("Step profile: undefined variable name")
+		})
+	})
+}
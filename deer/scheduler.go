@@ -0,0 +1,204 @@
+package deer
+
+import (
+	"context"
+	"hash/fnv"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Clock provides current time and timers, so that scheduling can be controlled in tests.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Scheduler runs jobs periodically, each job in its own goroutine.
+// Job is never run concurrently with itself, ticks missed while it is running are skipped.
+type Scheduler struct {
+	// skipped is accessed atomically, kept first for 64-bit alignment
+	skipped uint64
+
+	clock Clock
+
+	mu      sync.Mutex
+	jobs    []*scheduledJob
+	running bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Job is a periodically run task.
+type Job struct {
+	// ID is used to derive stable offset of runs within the interval,
+	// so that jobs with the same interval do not run at the same time.
+	ID       string
+	Interval time.Duration
+	// Jitter is maximum random delay added to each run.
+	Jitter time.Duration
+	Fn     func(context.Context)
+}
+
+type scheduledJob struct {
+	Job
+	cancel context.CancelFunc
+}
+
+// NewScheduler creates scheduler instance.
+// When clock is nil, wall clock is used.
+func NewScheduler(clock Clock) *Scheduler {
+	if clock == nil {
+		clock = realClock{}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Scheduler{
+		clock:  clock,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Every registers fn to be run every interval.
+func (s *Scheduler) Every(interval time.Duration, fn func(context.Context)) {
+	s.Add(Job{Interval: interval, Fn: fn})
+}
+
+// Add registers job, jobs added to running scheduler are started immediately.
+// Runs are aligned to the interval since unix epoch and shifted by offset derived from job ID,
+// therefore schedule is the same across restarts.
+func (s *Scheduler) Add(j Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sj := &scheduledJob{Job: j}
+	s.jobs = append(s.jobs, sj)
+	if s.running {
+		s.start(sj)
+	}
+}
+
+// Remove unregisters all jobs with given ID, their in-flight runs are cancelled.
+func (s *Scheduler) Remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := s.jobs[:0]
+	for _, sj := range s.jobs {
+		if sj.ID != id {
+			jobs = append(jobs, sj)
+		} else if sj.cancel != nil {
+			sj.cancel()
+		}
+	}
+	s.jobs = jobs
+}
+
+// Run starts all registered jobs and blocks until ctx is cancelled or scheduler is stopped.
+// Context passed to jobs is cancelled at the same time.
+func (s *Scheduler) Run(ctx context.Context) {
+	s.mu.Lock()
+	s.running = true
+	for _, sj := range s.jobs {
+		s.start(sj)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		s.cancel()
+	case <-s.ctx.Done():
+	}
+}
+
+// Stop cancels running jobs and waits until they return or ctx is done.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Skipped returns number of runs skipped because job was still running from the previous tick.
+func (s *Scheduler) Skipped() uint64 {
+	return atomic.LoadUint64(&s.skipped)
+}
+
+func (s *Scheduler) start(sj *scheduledJob) {
+	var ctx context.Context
+	ctx, sj.cancel = context.WithCancel(s.ctx)
+
+	s.wg.Add(1)
+	go s.loop(ctx, &sj.Job)
+}
+
+func (s *Scheduler) loop(ctx context.Context, j *Job) {
+	defer s.wg.Done()
+
+	offset := j.offset()
+	jitter := rand.New(rand.NewSource(int64(j.hash())))
+
+	next := nextTick(s.clock.Now(), j.Interval, offset)
+	for {
+		at := next
+		if j.Jitter > 0 {
+			at = at.Add(time.Duration(jitter.Int63n(int64(j.Jitter))))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.clock.After(at.Sub(s.clock.Now())):
+		}
+
+		j.Fn(ctx)
+
+		// skip ticks missed while job was running
+		ran := next
+		next = nextTick(s.clock.Now(), j.Interval, offset)
+		if missed := next.Sub(ran)/j.Interval - 1; missed > 0 {
+			atomic.AddUint64(&s.skipped, uint64(missed))
+		}
+	}
+}
+
+// offset returns shift of runs within the interval derived from job ID.
+func (j *Job) offset() time.Duration {
+	return time.Duration(j.hash() % uint64(j.Interval))
+}
+
+func (j *Job) hash() uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(j.ID))
+	return h.Sum64()
+}
+
+// nextTick returns first time after now which is offset from multiple of interval since unix epoch.
+func nextTick(now time.Time, interval, offset time.Duration) time.Time {
+	since := time.Duration(now.UnixNano()) - offset
+	return now.Add(interval - since%interval)
+}
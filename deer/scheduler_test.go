@@ -0,0 +1,270 @@
+package deer
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/franela/goblin"
+)
+
+// fakeClock is a manually advanced clock.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2020, 11, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, fakeWaiter{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+// Advance moves clock forward and fires due timers.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	waiters := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			waiters = append(waiters, w)
+		} else {
+			w.ch <- c.now
+		}
+	}
+	c.waiters = waiters
+}
+
+// AdvanceToNextTimer moves clock to the earliest pending timer and fires it.
+func (c *fakeClock) AdvanceToNextTimer() {
+	c.mu.Lock()
+	next := c.waiters[0].at
+	for _, w := range c.waiters {
+		if w.at.Before(next) {
+			next = w.at
+		}
+	}
+	d := next.Sub(c.now)
+	c.mu.Unlock()
+
+	c.Advance(d)
+}
+
+// WaitForTimers blocks until n timers are pending.
+func (c *fakeClock) WaitForTimers(n int) {
+	for {
+		c.mu.Lock()
+		pending := len(c.waiters)
+		c.mu.Unlock()
+		if pending >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	g := goblin.Goblin(t)
+	g.Describe("Scheduler", func() {
+		g.It("Runs job every interval", func() {
+			clock := newFakeClock()
+			s := NewScheduler(clock)
+			runs := make(chan time.Time, 10)
+			s.Every(500*time.Millisecond, func(ctx context.Context) {
+				runs <- clock.Now()
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go s.Run(ctx)
+			start := clock.Now()
+
+			clock.WaitForTimers(1)
+			clock.AdvanceToNextTimer()
+			first := <-runs
+			g.Assert(first.After(start)).IsTrue()
+			g.Assert(first.Sub(start) <= 500*time.Millisecond).IsTrue()
+
+			clock.WaitForTimers(1)
+			clock.AdvanceToNextTimer()
+			g.Assert((<-runs).Sub(first)).Equal(500 * time.Millisecond)
+
+			g.Assert(s.Stop(context.Background())).IsNil()
+		})
+
+		g.It("Skips ticks missed while job is running", func() {
+			clock := newFakeClock()
+			s := NewScheduler(clock)
+			runs := make(chan time.Time, 10)
+			slow := true
+			s.Every(time.Second, func(ctx context.Context) {
+				runs <- clock.Now()
+				if slow {
+					slow = false
+					clock.Advance(2500 * time.Millisecond)
+				}
+			})
+
+			go s.Run(context.Background())
+
+			clock.WaitForTimers(1)
+			clock.AdvanceToNextTimer()
+			first := <-runs
+
+			clock.WaitForTimers(1)
+			clock.AdvanceToNextTimer()
+			g.Assert((<-runs).Sub(first)).Equal(3 * time.Second)
+			g.Assert(s.Skipped()).Equal(uint64(2))
+
+			g.Assert(s.Stop(context.Background())).IsNil()
+		})
+
+		g.It("Starts jobs registered while running", func() {
+			clock := newFakeClock()
+			s := NewScheduler(clock)
+			go s.Run(context.Background())
+
+			runs := make(chan bool, 1)
+			s.Every(time.Second, func(ctx context.Context) {
+				runs <- true
+			})
+			clock.WaitForTimers(1)
+			clock.AdvanceToNextTimer()
+
+			g.Assert(<-runs).IsTrue()
+			g.Assert(s.Stop(context.Background())).IsNil()
+		})
+
+		g.It("Removes job and cancels its in-flight run", func() {
+			clock := newFakeClock()
+			s := NewScheduler(clock)
+			started := make(chan bool)
+			cancelled := make(chan bool)
+			s.Add(Job{ID: "slow", Interval: time.Second, Fn: func(ctx context.Context) {
+				started <- true
+				<-ctx.Done()
+				cancelled <- true
+			}})
+			go s.Run(context.Background())
+
+			clock.WaitForTimers(1)
+			clock.AdvanceToNextTimer()
+			<-started
+			s.Remove("slow")
+
+			g.Assert(<-cancelled).IsTrue()
+			g.Assert(len(s.jobs)).Equal(0)
+			g.Assert(s.Stop(context.Background())).IsNil()
+		})
+
+		g.It("Cancels in-flight job on stop", func() {
+			clock := newFakeClock()
+			s := NewScheduler(clock)
+			started := make(chan bool)
+			s.Every(time.Second, func(ctx context.Context) {
+				started <- true
+				<-ctx.Done()
+			})
+
+			returned := make(chan bool)
+			go func() {
+				s.Run(context.Background())
+				returned <- true
+			}()
+			clock.WaitForTimers(1)
+			clock.AdvanceToNextTimer()
+			<-started
+
+			g.Assert(s.Stop(context.Background())).IsNil()
+			g.Assert(<-returned).IsTrue()
+		})
+
+		g.It("Gives up waiting when stop context is done", func() {
+			s := NewScheduler(newFakeClock())
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			s.wg.Add(1)
+			g.Assert(s.Stop(ctx)).Equal(context.Canceled)
+			s.wg.Done()
+		})
+
+		g.Describe("Staggering", func() {
+			firstRun := func(clock *fakeClock, j Job) time.Time {
+				s := NewScheduler(clock)
+				runs := make(chan time.Time, 1)
+				j.Fn = func(ctx context.Context) {
+					runs <- clock.Now()
+				}
+				s.Add(j)
+				go s.Run(context.Background())
+				defer s.Stop(context.Background())
+
+				clock.WaitForTimers(1)
+				clock.AdvanceToNextTimer()
+				return <-runs
+			}
+
+			g.It("Spreads first runs of jobs across the interval", func() {
+				start := newFakeClock().Now()
+				a := firstRun(newFakeClock(), Job{ID: "aws/api/http/0", Interval: time.Minute})
+				b := firstRun(newFakeClock(), Job{ID: "aws/api/http/1", Interval: time.Minute})
+
+				g.Assert(a.Equal(b)).IsFalse()
+				g.Assert(a.Sub(start) <= time.Minute).IsTrue()
+				g.Assert(b.Sub(start) <= time.Minute).IsTrue()
+			})
+
+			g.It("Keeps schedule stable across restarts", func() {
+				j := Job{ID: "aws/api/http/0", Interval: time.Minute}
+				restarted := newFakeClock()
+				restarted.Advance(17 * time.Second)
+
+				a := firstRun(newFakeClock(), j)
+				b := firstRun(restarted, j)
+
+				g.Assert(b.Sub(a) % time.Minute).Equal(time.Duration(0))
+				g.Assert(time.Duration(a.UnixNano()) % time.Minute).Equal(j.offset())
+			})
+
+			g.It("Delays runs by at most jitter", func() {
+				j := Job{ID: "aws/api/http/0", Interval: time.Minute}
+				tick := firstRun(newFakeClock(), j)
+
+				j.Jitter = 10 * time.Second
+				a := firstRun(newFakeClock(), j)
+				b := firstRun(newFakeClock(), j)
+
+				g.Assert(a.Equal(b)).IsTrue()
+				g.Assert(a.Sub(tick) >= 0).IsTrue()
+				g.Assert(a.Sub(tick) < 10*time.Second).IsTrue()
+			})
+		})
+	})
+}
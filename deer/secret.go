@@ -0,0 +1,47 @@
+package deer
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// readSecret reads secret value from env variable or file (whichever is set).
+// Trailing newlines are trimmed from files.
+func readSecret(env, file string) (string, error) {
+	switch {
+	case env != "" && file != "":
+		return "", fmt.Errorf("Secret cannot be read from both env and file")
+
+	case env != "":
+		v, ok := os.LookupEnv(env)
+		if !ok {
+			return "", fmt.Errorf("Env variable %s is not set", env)
+		}
+		return v, nil
+
+	case file != "":
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(b), "\r\n"), nil
+	}
+
+	return "", fmt.Errorf("Secret requires env or file")
+}
+
+// readSecretOrValue returns inlined value or reads it from env variable or file.
+func readSecretOrValue(value, env, file string) (string, error) {
+	if env == "" && file == "" {
+		if value == "" {
+			return "", fmt.Errorf("Value, env or file is required")
+		}
+		return value, nil
+	}
+	if value != "" {
+		return "", fmt.Errorf("Value cannot be set together with env or file")
+	}
+	return readSecret(env, file)
+}
@@ -0,0 +1,234 @@
+package deer
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// SMTPCheck defines smtp type check.
+// It reads the greeting, sends EHLO and optionally upgrades connection with STARTTLS.
+type SMTPCheck struct {
+	ref
+
+	// body
+	IntervalSec     float64  `hcl:"interval"`
+	TimeoutSec      uint64   `hcl:"timeout"`
+	JitterSec       uint64   `hcl:"jitter,optional"`
+	Retries         uint64   `hcl:"retries,optional"`
+	RetryDelaySec   uint64   `hcl:"retry_delay,optional"`
+	ConfirmFailures uint64   `hcl:"confirm_failures,optional"`
+	Addr            string   `hcl:"addr"`
+	Hello           string   `hcl:"hello,optional"`
+	StartTLS        bool     `hcl:"starttls,optional"`
+	ServerName      string   `hcl:"server_name,optional"`
+	Expectations    []Expect `hcl:"expect,block"`
+}
+
+// SMTPResponse contains the result of the smtp check.
+type SMTPResponse struct {
+	Err        error
+	Banner     string
+	Extensions []string
+	TLS        *TLSDetails
+	Trace      Trace
+}
+
+// Validate ensures correct values are set for smtp check.
+func (c *SMTPCheck) Validate() error {
+	if err := validateSchedule(c.IntervalSec, c.TimeoutSec, c.JitterSec, c.retryPolicy()); err != nil {
+		return err
+	}
+
+	if len(c.Addr) == 0 {
+		return fmt.Errorf("Addr cannot be empty")
+	}
+	host, _, err := net.SplitHostPort(c.Addr)
+	if err != nil {
+		return fmt.Errorf("Addr must be in host:port format")
+	}
+	if c.Hello == "" {
+		c.Hello = "ohdeer.localhost"
+	}
+	if c.ServerName == "" {
+		c.ServerName = host
+	}
+
+	for _, expect := range c.Expectations {
+		switch expect.Subject {
+		case "banner":
+			if err := expect.validateMatcher("Banner"); err != nil {
+				return err
+			}
+		case "cert_days_left":
+			if !c.StartTLS {
+				return fmt.Errorf("Cert days left expectation requires starttls")
+			}
+			if expect.Min == nil && expect.Max == nil {
+				return fmt.Errorf("Cert days left expectation requires min or max")
+			}
+		default:
+			return fmt.Errorf("Invalid expectation subject")
+		}
+	}
+
+	return nil
+}
+
+// Interval returns how often check should be run.
+func (c *SMTPCheck) Interval() time.Duration {
+	return seconds(c.IntervalSec)
+}
+
+// Jitter returns maximum random delay added to each run.
+func (c *SMTPCheck) Jitter() time.Duration {
+	return time.Duration(c.JitterSec) * time.Second
+}
+
+// target returns addr, from which check ID is derived.
+func (c *SMTPCheck) target() string {
+	return c.Addr
+}
+
+// retryPolicy returns how failed probes are retried.
+func (c *SMTPCheck) retryPolicy() retryPolicy {
+	return retryPolicy{c.Retries, c.RetryDelaySec, c.ConfirmFailures}
+}
+
+// RunFn returns task function to run check.
+func (c *SMTPCheck) RunFn(s Store) func(context.Context) {
+	store := s
+
+	return func(ctx context.Context) {
+		c.ref.run(ctx, store, c.retryPolicy(), "", func() CheckResult {
+			now := time.Now()
+			resp := c.Dial(ctx, time.Duration(c.TimeoutSec)*time.Second)
+
+			result := c.ref.result(now)
+			result.Trace = &resp.Trace
+			result.verdict(c.Verify(resp))
+			result.Details = &Details{TCP: &TCPDetails{Banner: resp.Banner}, TLS: resp.TLS}
+
+			return result
+		})
+	}
+}
+
+// Dial talks to smtp server: reads greeting, sends EHLO, optionally STARTTLS and QUIT.
+func (c *SMTPCheck) Dial(ctx context.Context, timeout time.Duration) *SMTPResponse {
+	var resp SMTPResponse
+
+	start := time.Now()
+	defer func() {
+		resp.Trace.Total = time.Since(start)
+	}()
+
+	conn, err := dialTCP(ctx, "tcp", c.Addr, timeout, &resp.Trace)
+	if err != nil {
+		resp.Err = err
+		return &resp
+	}
+	defer conn.Close()
+	defer closeOnDone(ctx, conn)()
+
+	if err := conn.SetDeadline(start.Add(timeout)); err != nil {
+		resp.Err = err
+		return &resp
+	}
+
+	text := textproto.NewConn(conn)
+	started := time.Now()
+	_, resp.Banner, err = text.ReadResponse(220)
+	resp.Trace.ServerProcessing = time.Since(started)
+	if err != nil {
+		resp.Err = fmt.Errorf("Greeting: %v", err)
+		return &resp
+	}
+
+	resp.Extensions, err = smtpHello(text, c.Hello)
+	if err != nil {
+		resp.Err = fmt.Errorf("EHLO: %v", err)
+		return &resp
+	}
+
+	if c.StartTLS {
+		if _, err := smtpCmd(text, 220, "STARTTLS"); err != nil {
+			resp.Err = fmt.Errorf("STARTTLS: %v", err)
+			return &resp
+		}
+
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: c.ServerName, InsecureSkipVerify: true})
+		started := time.Now()
+		err := tlsConn.Handshake()
+		resp.Trace.TLSHandshake = time.Since(started)
+		if err != nil {
+			resp.Err = err
+			return &resp
+		}
+		details := inspectCertificates(tlsConn.ConnectionState().PeerCertificates, c.ServerName, time.Now())
+		resp.TLS = &details
+
+		text = textproto.NewConn(tlsConn)
+		if _, err := smtpHello(text, c.Hello); err != nil {
+			resp.Err = fmt.Errorf("EHLO: %v", err)
+			return &resp
+		}
+	}
+
+	smtpCmd(text, 221, "QUIT")
+
+	return &resp
+}
+
+// Check verifies if check is valid or not.
+func (c *SMTPCheck) Check(resp *SMTPResponse) bool {
+	return c.Verify(resp) == nil
+}
+
+// Verify returns the reason why check is not valid or nil when it passes.
+func (c *SMTPCheck) Verify(resp *SMTPResponse) error {
+	if resp.Err != nil {
+		return resp.Err
+	}
+
+	for _, expect := range c.Expectations {
+		switch expect.Subject {
+		case "banner":
+			if err := expect.verifyString("Banner", resp.Banner); err != nil {
+				return err
+			}
+		case "cert_days_left":
+			if err := expect.verifyRange("Certificate days left", resp.TLS.DaysLeft); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func smtpCmd(text *textproto.Conn, code int, format string, args ...interface{}) (string, error) {
+	id, err := text.Cmd(format, args...)
+	if err != nil {
+		return "", err
+	}
+	text.StartResponse(id)
+	defer text.EndResponse(id)
+
+	_, msg, err := text.ReadResponse(code)
+	return msg, err
+}
+
+// smtpHello sends EHLO and returns list of supported extensions.
+func smtpHello(text *textproto.Conn, hello string) ([]string, error) {
+	msg, err := smtpCmd(text, 250, "EHLO %s", hello)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(msg, "\n")
+	return lines[1:], nil
+}
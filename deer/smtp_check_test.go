@@ -0,0 +1,120 @@
+package deer
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/franela/goblin"
+)
+
+// smtpStandIn is a minimal smtp server supporting EHLO, STARTTLS and QUIT.
+func smtpStandIn(ln net.Listener, config *tls.Config) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go func(conn net.Conn) {
+			defer conn.Close()
+			conn.Write([]byte("220 mx.ohdeer.dev ESMTP ready\r\n"))
+
+			r := bufio.NewReader(conn)
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+				case strings.HasPrefix(cmd, "EHLO"):
+					conn.Write([]byte("250-mx.ohdeer.dev\r\n250-PIPELINING\r\n250 STARTTLS\r\n"))
+				case cmd == "STARTTLS":
+					conn.Write([]byte("220 Ready to start TLS\r\n"))
+					conn = tls.Server(conn, config)
+					r = bufio.NewReader(conn)
+				case cmd == "QUIT":
+					conn.Write([]byte("221 Bye\r\n"))
+					return
+				default:
+					conn.Write([]byte("502 Command not implemented\r\n"))
+				}
+			}
+		}(conn)
+	}
+}
+
+func TestSMTPCheck(t *testing.T) {
+	g := goblin.Goblin(t)
+	g.Describe("SMTPCheck", func() {
+		var (
+			ln  net.Listener
+			srv *httptest.Server
+		)
+
+		g.Before(func() {
+			var err error
+			ln, err = net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			go smtpStandIn(ln, &tls.Config{Certificates: srv.TLS.Certificates})
+		})
+
+		g.After(func() {
+			ln.Close()
+			srv.Close()
+		})
+
+		g.It("Reads greeting and extensions", func() {
+			check := &SMTPCheck{
+				Addr:         ln.Addr().String(),
+				Hello:        "ohdeer.localhost",
+				Expectations: []Expect{{Subject: "banner", Contains: "ESMTP"}},
+			}
+			resp := check.Dial(context.Background(), time.Second)
+
+			g.Assert(resp.Err).IsNil()
+			g.Assert(resp.Banner).Equal("mx.ohdeer.dev ESMTP ready")
+			g.Assert(resp.Extensions).Equal([]string{"PIPELINING", "STARTTLS"})
+			g.Assert(resp.TLS == nil).IsTrue()
+			g.Assert(check.Check(resp)).IsTrue()
+		})
+
+		g.It("Upgrades with starttls and inspects certificate", func() {
+			min := 14
+			check := &SMTPCheck{
+				Addr:         ln.Addr().String(),
+				Hello:        "ohdeer.localhost",
+				StartTLS:     true,
+				ServerName:   "example.com",
+				Expectations: []Expect{{Subject: "cert_days_left", Min: &min}},
+			}
+			resp := check.Dial(context.Background(), time.Second)
+
+			g.Assert(resp.Err).IsNil()
+			g.Assert(resp.TLS.DaysLeft > 14).IsTrue()
+			g.Assert(resp.TLS.HostnameValid).IsTrue()
+			g.Assert(check.Check(resp)).IsTrue()
+
+			min = 100000
+			g.Assert(check.Check(resp)).IsFalse()
+		})
+
+		g.It("Fails when greeting does not match", func() {
+			check := &SMTPCheck{
+				Addr:         ln.Addr().String(),
+				Hello:        "ohdeer.localhost",
+				Expectations: []Expect{{Subject: "banner", Matches: "^imap"}},
+			}
+
+			g.Assert(check.Check(check.Dial(context.Background(), time.Second))).IsFalse()
+		})
+	})
+}
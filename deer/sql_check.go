@@ -0,0 +1,202 @@
+package deer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql" // mysql adapter
+	_ "github.com/lib/pq"              // postgres adapter
+	"github.com/qbart/ohtea/tea"
+)
+
+// sqlOpen opens database handle, replaced in tests with in-process driver.
+var sqlOpen = sql.Open
+
+// SQLCheck defines database connectivity check.
+// It is used for both postgres and mysql blocks.
+type SQLCheck struct {
+	ref
+
+	// body
+	IntervalSec     float64  `hcl:"interval"`
+	TimeoutSec      uint64   `hcl:"timeout"`
+	JitterSec       uint64   `hcl:"jitter,optional"`
+	Retries         uint64   `hcl:"retries,optional"`
+	RetryDelaySec   uint64   `hcl:"retry_delay,optional"`
+	ConfirmFailures uint64   `hcl:"confirm_failures,optional"`
+	DSN             string   `hcl:"dsn,optional"`
+	DSNEnv          string   `hcl:"dsn_env,optional"`
+	DSNFile         string   `hcl:"dsn_file,optional"`
+	Query           string   `hcl:"query,optional"`
+	Expectations    []Expect `hcl:"expect,block"`
+
+	driver string
+	dsn    string
+}
+
+// SQLResponse contains the result of the query.
+type SQLResponse struct {
+	Err    error
+	Result string
+	Trace  Trace
+}
+
+// QueryDetails contains first value returned by the query or command.
+type QueryDetails struct {
+	Result string `json:"result"`
+}
+
+// Validate ensures correct values are set for database check.
+func (q *SQLCheck) Validate() error {
+	if err := validateSchedule(q.IntervalSec, q.TimeoutSec, q.JitterSec, q.retryPolicy()); err != nil {
+		return err
+	}
+
+	dsn, err := readSecretOrValue(q.DSN, q.DSNEnv, q.DSNFile)
+	if err != nil {
+		return fmt.Errorf("DSN: %v", err)
+	}
+	q.dsn = dsn
+
+	if q.Query == "" {
+		q.Query = "SELECT 1"
+	}
+
+	for _, expect := range q.Expectations {
+		switch expect.Subject {
+		case "result":
+			if err := expect.validateMatcher("Result"); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("Invalid expectation subject")
+		}
+	}
+
+	return nil
+}
+
+// Interval returns how often check should be run.
+func (q *SQLCheck) Interval() time.Duration {
+	return seconds(q.IntervalSec)
+}
+
+// Jitter returns maximum random delay added to each run.
+func (q *SQLCheck) Jitter() time.Duration {
+	return time.Duration(q.JitterSec) * time.Second
+}
+
+// target returns driver and dsn source, from which check ID is derived.
+func (q *SQLCheck) target() string {
+	return q.driver + " " + q.DSN + q.DSNEnv + q.DSNFile
+}
+
+// retryPolicy returns how failed probes are retried.
+func (q *SQLCheck) retryPolicy() retryPolicy {
+	return retryPolicy{q.Retries, q.RetryDelaySec, q.ConfirmFailures}
+}
+
+// RunFn returns task function to run check.
+func (q *SQLCheck) RunFn(s Store) func(context.Context) {
+	store := s
+
+	return func(ctx context.Context) {
+		q.ref.run(ctx, store, q.retryPolicy(), "", func() CheckResult {
+			now := time.Now()
+			resp := q.Exec(ctx, time.Duration(q.TimeoutSec)*time.Second)
+
+			result := q.ref.result(now)
+			result.Trace = &resp.Trace
+			result.verdict(q.Verify(resp))
+			if resp.Err == nil {
+				result.Details = &Details{Query: &QueryDetails{Result: resp.Result}}
+			}
+
+			return result
+		})
+	}
+}
+
+// Exec connects to the database and runs the query.
+// Connection time is stored as TCP connection and query time as server processing.
+func (q *SQLCheck) Exec(ctx context.Context, timeout time.Duration) *SQLResponse {
+	var resp SQLResponse
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	defer func() {
+		resp.Trace.Total = time.Since(start)
+	}()
+
+	db, err := sqlOpen(q.driver, q.dsn)
+	if err != nil {
+		resp.Err = err
+		return &resp
+	}
+	defer db.Close()
+
+	conn, err := db.Conn(ctx)
+	resp.Trace.TCPConnection = time.Since(start)
+	if err != nil {
+		resp.Err = err
+		return &resp
+	}
+	defer conn.Close()
+
+	started := time.Now()
+	resp.Result, resp.Err = queryFirstValue(ctx, conn, q.Query)
+	resp.Trace.ServerProcessing = time.Since(started)
+
+	return &resp
+}
+
+// Check verifies if check is valid or not.
+func (q *SQLCheck) Check(resp *SQLResponse) bool {
+	return q.Verify(resp) == nil
+}
+
+// Verify returns the reason why check is not valid or nil when it passes.
+func (q *SQLCheck) Verify(resp *SQLResponse) error {
+	if resp.Err != nil {
+		return resp.Err
+	}
+
+	for _, expect := range q.Expectations {
+		switch expect.Subject {
+		case "result":
+			if err := expect.verifyString("Result", resp.Result); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// queryFirstValue returns first column of the first row (empty if there are no rows).
+func queryFirstValue(ctx context.Context, conn *sql.Conn, query string) (string, error) {
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil || len(cols) == 0 || !rows.Next() {
+		return "", tea.ErrCoalesce(err, rows.Err())
+	}
+
+	values := make([]sql.NullString, len(cols))
+	dest := make([]interface{}, len(cols))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return "", err
+	}
+
+	return values[0].String, nil
+}
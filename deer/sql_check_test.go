@@ -0,0 +1,180 @@
+package deer
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/franela/goblin"
+)
+
+// fakeDB is in-process database stand-in returning the same rows for every query.
+type fakeDB struct {
+	rows    [][]driver.Value
+	err     error
+	queries []string
+}
+
+func (db *fakeDB) Connect(ctx context.Context) (driver.Conn, error) { return &fakeConn{db: db}, nil }
+func (db *fakeDB) Driver() driver.Driver                            { return nil }
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("Prepare not supported")
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return nil, fmt.Errorf("Begin not supported") }
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.queries = append(c.db.queries, query)
+	if c.db.err != nil {
+		return nil, c.db.err
+	}
+	return &fakeRows{rows: c.db.rows}, nil
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	if len(r.rows) == 0 {
+		return []string{"value"}
+	}
+	cols := make([]string, len(r.rows[0]))
+	for i := range cols {
+		cols[i] = fmt.Sprint("col", i)
+	}
+	return cols
+}
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestSQLCheck(t *testing.T) {
+	g := goblin.Goblin(t)
+	g.Describe("SQLCheck", func() {
+		// requires running postgres, see Makefile
+		if dsn := os.Getenv("DATABASE_URL"); dsn != "" {
+			g.It("Queries postgres", func() {
+				check := &SQLCheck{
+					driver:       "postgres",
+					dsn:          dsn,
+					Query:        "SELECT 1",
+					Expectations: []Expect{{Subject: "result", Equals: "1"}},
+				}
+
+				g.Assert(check.Verify(check.Exec(context.Background(), 5*time.Second))).IsNil()
+			})
+		}
+
+		g.It("Fails when database is unreachable", func() {
+			check := &SQLCheck{
+				driver: "postgres",
+				dsn:    "postgres://ohdeer@127.0.0.1:1/deer?sslmode=disable&connect_timeout=1",
+				Query:  "SELECT 1",
+			}
+
+			g.Assert(check.Check(check.Exec(context.Background(), time.Second))).IsFalse()
+		})
+
+		g.It("Fails when mysql is unreachable", func() {
+			check := &SQLCheck{
+				driver: "mysql",
+				dsn:    "ohdeer@tcp(127.0.0.1:1)/deer?timeout=1s",
+				Query:  "SELECT 1",
+			}
+
+			g.Assert(check.Check(check.Exec(context.Background(), time.Second))).IsFalse()
+		})
+
+		g.Describe("Fake driver", func() {
+			var (
+				db     *fakeDB
+				opened []string
+			)
+
+			g.BeforeEach(func() {
+				db = &fakeDB{}
+				opened = nil
+				sqlOpen = func(driverName, dsn string) (*sql.DB, error) {
+					opened = append(opened, driverName+" "+dsn)
+					return sql.OpenDB(db), nil
+				}
+			})
+
+			g.AfterEach(func() {
+				sqlOpen = sql.Open
+			})
+
+			for _, driverName := range []string{"postgres", "mysql"} {
+				driverName := driverName
+
+				g.It("Queries "+driverName+" and matches first value", func() {
+					db.rows = [][]driver.Value{{int64(12), "ignored"}, {int64(13), "ignored"}}
+					check := &SQLCheck{
+						driver:       driverName,
+						dsn:          "app",
+						Query:        "SELECT COUNT(*), 'x' FROM jobs",
+						Expectations: []Expect{{Subject: "result", Equals: "12"}},
+					}
+					resp := check.Exec(context.Background(), time.Second)
+
+					g.Assert(check.Verify(resp)).IsNil()
+					g.Assert(resp.Result).Equal("12")
+					g.Assert(opened).Equal([]string{driverName + " app"})
+					g.Assert(db.queries).Equal([]string{"SELECT COUNT(*), 'x' FROM jobs"})
+				})
+
+				g.It("Fails "+driverName+" check on query error", func() {
+					db.err = fmt.Errorf("relation \"jobs\" does not exist")
+					check := &SQLCheck{driver: driverName, Query: "SELECT COUNT(*) FROM jobs"}
+
+					g.Assert(check.Verify(check.Exec(context.Background(), time.Second)).Error()).Equal("relation \"jobs\" does not exist")
+				})
+			}
+
+			g.It("Returns empty result when there are no rows", func() {
+				check := &SQLCheck{
+					driver:       "postgres",
+					Query:        "SELECT id FROM jobs WHERE failed",
+					Expectations: []Expect{{Subject: "result", Equals: "1"}},
+				}
+				resp := check.Exec(context.Background(), time.Second)
+
+				g.Assert(resp.Err).IsNil()
+				g.Assert(resp.Result).Equal("")
+				g.Assert(check.Check(resp)).IsFalse()
+			})
+
+			g.It("Saves result details", func() {
+				db.rows = [][]driver.Value{{"ok"}}
+				store := &testStore{}
+				check := &SQLCheck{IntervalSec: 30, TimeoutSec: 1, Query: "SELECT 'ok'"}
+				check.bind(&Monitor{ID: "aws"}, &Service{ID: "db"}, "aws/db/sql/0")
+				check.driver = "mysql"
+
+				check.RunFn(store)(context.Background())
+
+				g.Assert(store.Results()[0].Success).IsTrue()
+				g.Assert(store.Results()[0].Details.Query.Result).Equal("ok")
+			})
+		})
+	})
+}
@@ -32,17 +32,21 @@ type ReadFilter struct {
 	Interval       uint
 	IntervalUnit   string
 	ActiveServices map[string][]string
+	// IPVersion limits metrics to checks run over given ip version (optional).
+	// Results of checks not run per ip version are always included.
+	IPVersion string
 }
 
 // Metric represents metric for given time bucket.
 type Metric struct {
-	MonitorID    string    `json:"monitor_id"`
-	ServiceID    string    `json:"service_id"`
-	Bucket       time.Time `json:"bucket"`
-	Health       float64   `json:"health"`
-	Details      Details   `json:"details"`
-	PassedChecks uint64    `json:"passed_checks"`
-	FailedChecks uint64    `json:"failed_checks"`
+	MonitorID      string    `json:"monitor_id"`
+	ServiceID      string    `json:"service_id"`
+	Bucket         time.Time `json:"bucket"`
+	Health         float64   `json:"health"`
+	Details        Details   `json:"details"`
+	PassedChecks   uint64    `json:"passed_checks"`
+	FailedChecks   uint64    `json:"failed_checks"`
+	DegradedChecks uint64    `json:"degraded_checks"`
 }
 
 // Until calculates when interval should stop.
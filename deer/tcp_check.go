@@ -0,0 +1,183 @@
+package deer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// TCPCheck defines tcp connect type check.
+type TCPCheck struct {
+	ref
+
+	// body
+	IntervalSec     float64  `hcl:"interval"`
+	TimeoutSec      uint64   `hcl:"timeout"`
+	JitterSec       uint64   `hcl:"jitter,optional"`
+	Retries         uint64   `hcl:"retries,optional"`
+	RetryDelaySec   uint64   `hcl:"retry_delay,optional"`
+	ConfirmFailures uint64   `hcl:"confirm_failures,optional"`
+	Addr            string   `hcl:"addr"`
+	Expectations    []Expect `hcl:"expect,block"`
+}
+
+// TCPResponse contains the result of the tcp check.
+type TCPResponse struct {
+	Err    error
+	Banner string
+	Trace  Trace
+}
+
+// Validate ensures correct values are set for tcp check.
+func (t *TCPCheck) Validate() error {
+	if err := validateSchedule(t.IntervalSec, t.TimeoutSec, t.JitterSec, t.retryPolicy()); err != nil {
+		return err
+	}
+
+	if len(t.Addr) == 0 {
+		return fmt.Errorf("Addr cannot be empty")
+	}
+	if _, _, err := net.SplitHostPort(t.Addr); err != nil {
+		return fmt.Errorf("Addr must be in host:port format")
+	}
+
+	for _, expect := range t.Expectations {
+		switch expect.Subject {
+		case "connects":
+		case "banner":
+			if err := expect.validateMatcher("Banner"); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("Invalid expectation subject")
+		}
+	}
+
+	return nil
+}
+
+// Interval returns how often check should be run.
+func (t *TCPCheck) Interval() time.Duration {
+	return seconds(t.IntervalSec)
+}
+
+// Jitter returns maximum random delay added to each run.
+func (t *TCPCheck) Jitter() time.Duration {
+	return time.Duration(t.JitterSec) * time.Second
+}
+
+// target returns addr, from which check ID is derived.
+func (t *TCPCheck) target() string {
+	return t.Addr
+}
+
+// retryPolicy returns how failed probes are retried.
+func (t *TCPCheck) retryPolicy() retryPolicy {
+	return retryPolicy{t.Retries, t.RetryDelaySec, t.ConfirmFailures}
+}
+
+// RunFn returns task function to run check.
+func (t *TCPCheck) RunFn(s Store) func(context.Context) {
+	store := s
+
+	return func(ctx context.Context) {
+		t.ref.run(ctx, store, t.retryPolicy(), "", func() CheckResult {
+			now := time.Now()
+			resp := t.Dial(ctx, time.Duration(t.TimeoutSec)*time.Second)
+
+			return t.ref.tcpResult(now, resp, t.Verify(resp))
+		})
+	}
+}
+
+// Dial connects to the address and reads the banner when any banner expectation is set.
+func (t *TCPCheck) Dial(ctx context.Context, timeout time.Duration) *TCPResponse {
+	return exchange(ctx, t.Addr, nil, t.expectsBanner(), timeout)
+}
+
+// Check verifies if check is valid or not.
+func (t *TCPCheck) Check(resp *TCPResponse) bool {
+	return t.Verify(resp) == nil
+}
+
+// Verify returns the reason why check is not valid or nil when it passes.
+func (t *TCPCheck) Verify(resp *TCPResponse) error {
+	if resp.Err != nil {
+		return resp.Err
+	}
+
+	return verifyBanner(t.Expectations, "banner", "Banner", resp.Banner)
+}
+
+// exchange connects to the address, sends data (if any) and reads a single line when read is set.
+// Whole exchange including connecting must finish within timeout.
+func exchange(ctx context.Context, addr string, send []byte, read bool, timeout time.Duration) *TCPResponse {
+	var resp TCPResponse
+
+	start := time.Now()
+	defer func() {
+		resp.Trace.Total = time.Since(start)
+	}()
+
+	conn, err := dialTCP(ctx, "tcp", addr, timeout, &resp.Trace)
+	if err != nil {
+		resp.Err = err
+		return &resp
+	}
+	defer conn.Close()
+	defer closeOnDone(ctx, conn)()
+
+	started := time.Now()
+	if len(send) > 0 {
+		if err := conn.SetWriteDeadline(start.Add(timeout)); err != nil {
+			resp.Err = err
+			return &resp
+		}
+		if _, err := conn.Write(send); err != nil {
+			resp.Err = err
+			return &resp
+		}
+	}
+	if read {
+		resp.Banner, resp.Err = readLine(conn, start.Add(timeout))
+		resp.Banner = strings.TrimRight(resp.Banner, "\r\n")
+		resp.Trace.ServerProcessing = time.Since(started)
+	}
+
+	return &resp
+}
+
+// tcpResult creates check result of tcp exchange.
+func (r *ref) tcpResult(at time.Time, resp *TCPResponse, err error) CheckResult {
+	result := r.result(at)
+	result.Trace = &resp.Trace
+	result.verdict(err)
+	if resp.Banner != "" {
+		result.Details = &Details{TCP: &TCPDetails{Banner: resp.Banner}}
+	}
+	return result
+}
+
+// verifyBanner matches banner against expectations with given subject.
+func verifyBanner(expectations []Expect, subject, what, banner string) error {
+	for _, expect := range expectations {
+		if expect.Subject != subject {
+			continue
+		}
+		if err := expect.verifyString(what, banner); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *TCPCheck) expectsBanner() bool {
+	for _, expect := range t.Expectations {
+		if expect.Subject == "banner" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package deer
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/franela/goblin"
+)
+
+func TestTCPCheck(t *testing.T) {
+	g := goblin.Goblin(t)
+	g.Describe("TCPCheck", func() {
+		var ln net.Listener
+
+		g.Before(func() {
+			var err error
+			ln, err = net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			go func() {
+				for {
+					conn, err := ln.Accept()
+					if err != nil {
+						return
+					}
+					conn.Write([]byte("SSH-2.0-OpenSSH_8.2\r\n"))
+					conn.Close()
+				}
+			}()
+		})
+
+		g.After(func() {
+			ln.Close()
+		})
+
+		g.It("Connects and matches banner", func() {
+			check := &TCPCheck{
+				Addr: ln.Addr().String(),
+				Expectations: []Expect{
+					{Subject: "connects"},
+					{Subject: "banner", Matches: "^SSH-2"},
+				},
+			}
+			resp := check.Dial(context.Background(), time.Second)
+
+			g.Assert(resp.Err).IsNil()
+			g.Assert(resp.Banner).Equal("SSH-2.0-OpenSSH_8.2")
+			g.Assert(check.Check(resp)).IsTrue()
+		})
+
+		g.It("Fails when banner does not match", func() {
+			check := &TCPCheck{
+				Addr:         ln.Addr().String(),
+				Expectations: []Expect{{Subject: "banner", Contains: "SMTP"}},
+			}
+
+			g.Assert(check.Check(check.Dial(context.Background(), time.Second))).IsFalse()
+		})
+
+		g.It("Fails when nothing listens", func() {
+			l, _ := net.Listen("tcp", "127.0.0.1:0")
+			addr := l.Addr().String()
+			l.Close()
+
+			check := &TCPCheck{Addr: addr}
+			resp := check.Dial(context.Background(), time.Second)
+
+			g.Assert(resp.Err == nil).IsFalse()
+			g.Assert(check.Check(resp)).IsFalse()
+		})
+	})
+}
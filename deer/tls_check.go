@@ -0,0 +1,241 @@
+package deer
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"time"
+)
+
+// TLSCheck defines tls certificate type check.
+type TLSCheck struct {
+	ref
+
+	// body
+	IntervalSec     float64  `hcl:"interval"`
+	TimeoutSec      uint64   `hcl:"timeout"`
+	JitterSec       uint64   `hcl:"jitter,optional"`
+	Retries         uint64   `hcl:"retries,optional"`
+	RetryDelaySec   uint64   `hcl:"retry_delay,optional"`
+	ConfirmFailures uint64   `hcl:"confirm_failures,optional"`
+	Addr            string   `hcl:"addr"`
+	ServerName      string   `hcl:"server_name,optional"`
+	Expectations    []Expect `hcl:"expect,block"`
+}
+
+// TLSResponse contains the result of the tls check.
+type TLSResponse struct {
+	Err     error
+	Details TLSDetails
+	Trace   Trace
+}
+
+// TLSDetails contains peer certificate details.
+type TLSDetails struct {
+	Subject string `json:"subject"`
+	Issuer  string `json:"issuer"`
+	// NotAfter and DaysLeft refer to the presented certificate which expires first,
+	// an intermediate can expire before the leaf.
+	NotAfter      time.Time `json:"not_after"`
+	DaysLeft      int       `json:"days_left"`
+	ExpiringFirst string    `json:"expiring_first"`
+	HostnameValid bool      `json:"hostname_valid"`
+	HostnameError string    `json:"hostname_error,omitempty"`
+	ChainValid    bool      `json:"chain_valid"`
+	ChainError    string    `json:"chain_error,omitempty"`
+}
+
+// Validate ensures correct values are set for tls check.
+func (t *TLSCheck) Validate() error {
+	if err := validateSchedule(t.IntervalSec, t.TimeoutSec, t.JitterSec, t.retryPolicy()); err != nil {
+		return err
+	}
+
+	if len(t.Addr) == 0 {
+		return fmt.Errorf("Addr cannot be empty")
+	}
+	if _, _, err := net.SplitHostPort(t.Addr); err != nil {
+		return fmt.Errorf("Addr must be in host:port format")
+	}
+
+	for _, expect := range t.Expectations {
+		switch expect.Subject {
+		case "cert_days_left":
+			if expect.Min == nil && expect.Max == nil {
+				return fmt.Errorf("Cert days left expectation requires min or max")
+			}
+		case "hostname_valid", "chain_valid":
+		default:
+			return fmt.Errorf("Invalid expectation subject")
+		}
+	}
+
+	return nil
+}
+
+// Interval returns how often check should be run.
+func (t *TLSCheck) Interval() time.Duration {
+	return seconds(t.IntervalSec)
+}
+
+// Jitter returns maximum random delay added to each run.
+func (t *TLSCheck) Jitter() time.Duration {
+	return time.Duration(t.JitterSec) * time.Second
+}
+
+// target returns addr, from which check ID is derived.
+func (t *TLSCheck) target() string {
+	return t.Addr
+}
+
+// retryPolicy returns how failed probes are retried.
+func (t *TLSCheck) retryPolicy() retryPolicy {
+	return retryPolicy{t.Retries, t.RetryDelaySec, t.ConfirmFailures}
+}
+
+// RunFn returns task function to run check.
+func (t *TLSCheck) RunFn(s Store) func(context.Context) {
+	store := s
+
+	return func(ctx context.Context) {
+		t.ref.run(ctx, store, t.retryPolicy(), "", func() CheckResult {
+			now := time.Now()
+			resp := t.Handshake(ctx, time.Duration(t.TimeoutSec)*time.Second)
+
+			result := t.ref.result(now)
+			result.Trace = &resp.Trace
+			result.verdict(t.Verify(resp))
+			if resp.Err == nil {
+				result.Details = &Details{TLS: &resp.Details}
+			}
+
+			return result
+		})
+	}
+}
+
+// Handshake connects to the address and inspects peer certificate chain.
+// Certificates are verified manually so the result can be reported even for invalid chains.
+func (t *TLSCheck) Handshake(ctx context.Context, timeout time.Duration) *TLSResponse {
+	var resp TLSResponse
+
+	start := time.Now()
+	defer func() {
+		resp.Trace.Total = time.Since(start)
+	}()
+
+	conn, err := dialTCP(ctx, "tcp", t.Addr, timeout, &resp.Trace)
+	if err != nil {
+		resp.Err = err
+		return &resp
+	}
+	defer conn.Close()
+	defer closeOnDone(ctx, conn)()
+
+	serverName := t.serverName()
+	client := tls.Client(conn, &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: true,
+	})
+	if err := client.SetDeadline(start.Add(timeout)); err != nil {
+		resp.Err = err
+		return &resp
+	}
+
+	started := time.Now()
+	err = client.Handshake()
+	resp.Trace.TLSHandshake = time.Since(started)
+	if err != nil {
+		resp.Err = err
+		return &resp
+	}
+
+	certs := client.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		resp.Err = fmt.Errorf("No peer certificates")
+		return &resp
+	}
+	resp.Details = inspectCertificates(certs, serverName, time.Now())
+
+	return &resp
+}
+
+// Check verifies if check is valid or not.
+func (t *TLSCheck) Check(resp *TLSResponse) bool {
+	return t.Verify(resp) == nil
+}
+
+// Verify returns the reason why check is not valid or nil when it passes.
+func (t *TLSCheck) Verify(resp *TLSResponse) error {
+	if resp.Err != nil {
+		return resp.Err
+	}
+
+	for _, expect := range t.Expectations {
+		switch expect.Subject {
+		case "cert_days_left":
+			if err := expect.verifyRange("Certificate days left", resp.Details.DaysLeft); err != nil {
+				return err
+			}
+		case "hostname_valid":
+			if !resp.Details.HostnameValid {
+				return fmt.Errorf("Invalid hostname: %s", resp.Details.HostnameError)
+			}
+		case "chain_valid":
+			if !resp.Details.ChainValid {
+				return fmt.Errorf("Invalid certificate chain: %s", resp.Details.ChainError)
+			}
+		}
+	}
+	return nil
+}
+
+func (t *TLSCheck) serverName() string {
+	if t.ServerName != "" {
+		return t.ServerName
+	}
+	host, _, _ := net.SplitHostPort(t.Addr)
+	return host
+}
+
+// inspectCertificates verifies leaf certificate against hostname and system roots
+// and finds the presented certificate which expires first.
+func inspectCertificates(certs []*x509.Certificate, serverName string, now time.Time) TLSDetails {
+	leaf := certs[0]
+	expiring := leaf
+	for _, c := range certs[1:] {
+		if c.NotAfter.Before(expiring.NotAfter) {
+			expiring = c
+		}
+	}
+	d := TLSDetails{
+		Subject:       leaf.Subject.String(),
+		Issuer:        leaf.Issuer.String(),
+		NotAfter:      expiring.NotAfter,
+		DaysLeft:      int(expiring.NotAfter.Sub(now).Hours() / 24),
+		ExpiringFirst: expiring.Subject.String(),
+	}
+
+	if err := leaf.VerifyHostname(serverName); err != nil {
+		d.HostnameError = err.Error()
+	} else {
+		d.HostnameValid = true
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, c := range certs[1:] {
+		intermediates.AddCert(c)
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{
+		CurrentTime:   now,
+		Intermediates: intermediates,
+	}); err != nil {
+		d.ChainError = err.Error()
+	} else {
+		d.ChainValid = true
+	}
+
+	return d
+}
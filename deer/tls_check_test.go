@@ -0,0 +1,105 @@
+package deer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/franela/goblin"
+)
+
+// issueCert creates certificate valid for given duration signed by parent (self-signed when parent is nil).
+func issueCert(name string, validFor time.Duration, ca bool, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              []string{name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(validFor),
+		IsCA:                  ca,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	if parent == nil {
+		parent, parentKey = tpl, key
+	}
+	der, _ := x509.CreateCertificate(rand.Reader, tpl, parent, &key.PublicKey, parentKey)
+	cert, _ := x509.ParseCertificate(der)
+	return cert, key
+}
+
+func TestTLSCheck(t *testing.T) {
+	g := goblin.Goblin(t)
+	g.Describe("TLSCheck", func() {
+		var srv *httptest.Server
+
+		g.Before(func() {
+			srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		})
+
+		g.After(func() {
+			srv.Close()
+		})
+
+		g.It("Inspects peer certificate", func() {
+			min := 14
+			check := &TLSCheck{
+				Addr:       strings.TrimPrefix(srv.URL, "https://"),
+				ServerName: "example.com",
+				Expectations: []Expect{
+					{Subject: "cert_days_left", Min: &min},
+					{Subject: "hostname_valid"},
+				},
+			}
+			resp := check.Handshake(context.Background(), time.Second)
+
+			g.Assert(resp.Err).IsNil()
+			g.Assert(resp.Details.DaysLeft > 14).IsTrue()
+			g.Assert(resp.Details.HostnameValid).IsTrue()
+			g.Assert(resp.Details.ChainValid).IsFalse()
+			g.Assert(check.Check(resp)).IsTrue()
+		})
+
+		g.It("Fails on untrusted chain and short expiry", func() {
+			min := 100000
+			check := &TLSCheck{
+				Addr: strings.TrimPrefix(srv.URL, "https://"),
+				Expectations: []Expect{
+					{Subject: "cert_days_left", Min: &min},
+				},
+			}
+			g.Assert(check.Check(check.Handshake(context.Background(), time.Second))).IsFalse()
+
+			check.Expectations = []Expect{{Subject: "chain_valid"}}
+			g.Assert(check.Check(check.Handshake(context.Background(), time.Second))).IsFalse()
+		})
+
+		g.It("Reports intermediate expiring before the leaf", func() {
+			root, rootKey := issueCert("Root CA", 3650*24*time.Hour, true, nil, nil)
+			intermediate, intermediateKey := issueCert("Intermediate CA", 5*24*time.Hour+time.Hour, true, root, rootKey)
+			leaf, _ := issueCert("example.com", 90*24*time.Hour, false, intermediate, intermediateKey)
+
+			details := inspectCertificates([]*x509.Certificate{leaf, intermediate}, "example.com", time.Now())
+
+			g.Assert(details.Subject).Equal("CN=example.com")
+			g.Assert(details.DaysLeft).Equal(5)
+			g.Assert(details.NotAfter).Equal(intermediate.NotAfter)
+			g.Assert(details.ExpiringFirst).Equal("CN=Intermediate CA")
+			g.Assert(details.HostnameValid).IsTrue()
+
+			min := 14
+			check := &TLSCheck{Expectations: []Expect{{Subject: "cert_days_left", Min: &min}}}
+			g.Assert(check.Check(&TLSResponse{Details: details})).IsFalse()
+		})
+	})
+}
@@ -0,0 +1,240 @@
+package deer
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"time"
+)
+
+// UDPCheck defines udp request/response type check.
+type UDPCheck struct {
+	ref
+
+	// body
+	IntervalSec     float64  `hcl:"interval"`
+	TimeoutSec      uint64   `hcl:"timeout"`
+	JitterSec       uint64   `hcl:"jitter,optional"`
+	Retries         uint64   `hcl:"retries,optional"`
+	RetryDelaySec   uint64   `hcl:"retry_delay,optional"`
+	ConfirmFailures uint64   `hcl:"confirm_failures,optional"`
+	Addr            string   `hcl:"addr"`
+	Payload         string   `hcl:"payload,optional"`
+	PayloadHex      string   `hcl:"payload_hex,optional"`
+	Expectations    []Expect `hcl:"expect,block"`
+
+	payload []byte
+}
+
+// UDPResponse contains the result of the udp check.
+type UDPResponse struct {
+	Err   error
+	Reply []byte
+	Trace Trace
+}
+
+// UDPDetails contains udp reply details.
+type UDPDetails struct {
+	Size  int    `json:"size"`
+	Reply string `json:"reply"`
+}
+
+// Validate ensures correct values are set for udp check.
+func (u *UDPCheck) Validate() error {
+	if err := validateSchedule(u.IntervalSec, u.TimeoutSec, u.JitterSec, u.retryPolicy()); err != nil {
+		return err
+	}
+
+	switch {
+	case len(u.Addr) == 0:
+		return fmt.Errorf("Addr cannot be empty")
+
+	case len(u.Payload) > 0 && len(u.PayloadHex) > 0:
+		return fmt.Errorf("Payload and payload_hex cannot be set together")
+
+	case len(u.Payload) == 0 && len(u.PayloadHex) == 0:
+		return fmt.Errorf("Payload or payload_hex is required")
+	}
+	if _, _, err := net.SplitHostPort(u.Addr); err != nil {
+		return fmt.Errorf("Addr must be in host:port format")
+	}
+
+	u.payload = []byte(u.Payload)
+	if len(u.PayloadHex) > 0 {
+		b, err := hex.DecodeString(u.PayloadHex)
+		if err != nil {
+			return fmt.Errorf("Invalid payload_hex: %v", err)
+		}
+		u.payload = b
+	}
+
+	for _, expect := range u.Expectations {
+		switch expect.Subject {
+		case "reply":
+			if expect.ContainsHex != "" {
+				if _, err := hex.DecodeString(expect.ContainsHex); err != nil {
+					return fmt.Errorf("Invalid contains_hex: %v", err)
+				}
+			}
+			// string matchers are optional only next to contains_hex, both are applied when set
+			hasMatcher := expect.Equals != "" || expect.Contains != "" || expect.NotContains != "" || expect.Matches != ""
+			if expect.ContainsHex == "" || hasMatcher {
+				if err := expect.validateMatcher("Reply"); err != nil {
+					return err
+				}
+			}
+		case "latency":
+			if len(expect.latencyLimits(&Trace{})) == 0 {
+				return fmt.Errorf("Latency expectation requires at least one limit")
+			}
+		default:
+			return fmt.Errorf("Invalid expectation subject")
+		}
+	}
+
+	return nil
+}
+
+// Interval returns how often check should be run.
+func (u *UDPCheck) Interval() time.Duration {
+	return seconds(u.IntervalSec)
+}
+
+// Jitter returns maximum random delay added to each run.
+func (u *UDPCheck) Jitter() time.Duration {
+	return time.Duration(u.JitterSec) * time.Second
+}
+
+// target returns addr, from which check ID is derived.
+func (u *UDPCheck) target() string {
+	return u.Addr
+}
+
+// retryPolicy returns how failed probes are retried.
+func (u *UDPCheck) retryPolicy() retryPolicy {
+	return retryPolicy{u.Retries, u.RetryDelaySec, u.ConfirmFailures}
+}
+
+// RunFn returns task function to run check.
+func (u *UDPCheck) RunFn(s Store) func(context.Context) {
+	store := s
+
+	return func(ctx context.Context) {
+		u.ref.run(ctx, store, u.retryPolicy(), "", func() CheckResult {
+			now := time.Now()
+			resp := u.Send(ctx, time.Duration(u.TimeoutSec)*time.Second)
+
+			result := u.ref.result(now)
+			result.Trace = &resp.Trace
+			result.verdict(u.Verify(resp))
+			if resp.Reply != nil {
+				result.Details = &Details{UDP: &UDPDetails{
+					Size:  len(resp.Reply),
+					Reply: truncate(hex.EncodeToString(resp.Reply), 128),
+				}}
+			}
+
+			return result
+		})
+	}
+}
+
+// Send writes the payload and waits for a single datagram reply.
+// Round-trip time is written to trace total.
+func (u *UDPCheck) Send(ctx context.Context, timeout time.Duration) *UDPResponse {
+	var resp UDPResponse
+
+	deadline := time.Now().Add(timeout)
+
+	dialCtx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	host, port, err := net.SplitHostPort(u.Addr)
+	if err != nil {
+		resp.Err = err
+		return &resp
+	}
+
+	started := time.Now()
+	ips, err := net.DefaultResolver.LookupIPAddr(dialCtx, host)
+	resp.Trace.DNSLookup = time.Since(started)
+	if err != nil {
+		resp.Err = err
+		return &resp
+	}
+
+	conn, err := (&net.Dialer{}).DialContext(dialCtx, "udp", net.JoinHostPort(ips[0].String(), port))
+	if err != nil {
+		resp.Err = err
+		return &resp
+	}
+	defer conn.Close()
+	defer closeOnDone(ctx, conn)()
+
+	if err := conn.SetDeadline(deadline); err != nil {
+		resp.Err = err
+		return &resp
+	}
+
+	started = time.Now()
+	if _, err := conn.Write(u.payload); err != nil {
+		resp.Err = err
+		return &resp
+	}
+
+	buf := make([]byte, 64*1024)
+	n, err := conn.Read(buf)
+	resp.Trace.Total = time.Since(started)
+	if err != nil {
+		resp.Err = err
+		return &resp
+	}
+	resp.Reply = buf[:n]
+
+	return &resp
+}
+
+// Check verifies if check is valid or not.
+func (u *UDPCheck) Check(resp *UDPResponse) bool {
+	err := u.Verify(resp)
+	_, degraded := err.(*DegradedError)
+	return err == nil || degraded
+}
+
+// Verify returns the reason why check is not valid or nil when it passes.
+func (u *UDPCheck) Verify(resp *UDPResponse) error {
+	if resp.Err != nil {
+		return resp.Err
+	}
+
+	var degraded error
+
+	for _, expect := range u.Expectations {
+		switch expect.Subject {
+		case "reply":
+			if expect.ContainsHex != "" {
+				want, _ := hex.DecodeString(expect.ContainsHex)
+				if !bytes.Contains(resp.Reply, want) {
+					return fmt.Errorf("Reply %s does not contain %s",
+						truncate(hex.EncodeToString(resp.Reply), 64), expect.ContainsHex)
+				}
+			}
+			if err := expect.verifyString("Reply", string(resp.Reply)); err != nil {
+				return err
+			}
+
+		case "latency":
+			err := expect.verifyLatency(&resp.Trace)
+			if _, ok := err.(*DegradedError); ok {
+				if degraded == nil {
+					degraded = err
+				}
+			} else if err != nil {
+				return err
+			}
+		}
+	}
+	return degraded
+}
@@ -0,0 +1,126 @@
+package deer
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/franela/goblin"
+)
+
+func TestUDPCheck(t *testing.T) {
+	g := goblin.Goblin(t)
+	g.Describe("UDPCheck", func() {
+		var conn net.PacketConn
+
+		g.Before(func() {
+			var err error
+			conn, err = net.ListenPacket("udp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			go func() {
+				buf := make([]byte, 512)
+				for {
+					n, addr, err := conn.ReadFrom(buf)
+					if err != nil {
+						return
+					}
+					if string(buf[:n]) == "drop" {
+						continue
+					}
+					reply := append([]byte{0x1c, 0x02}, buf[:n]...)
+					conn.WriteTo(reply, addr)
+				}
+			}()
+		})
+
+		g.After(func() {
+			conn.Close()
+		})
+
+		g.It("Sends string payload and matches reply", func() {
+			check := &UDPCheck{
+				IntervalSec:  10,
+				TimeoutSec:   1,
+				Addr:         conn.LocalAddr().String(),
+				Payload:      "status",
+				Expectations: []Expect{{Subject: "reply", Matches: "status$"}},
+			}
+			g.Assert(check.Validate()).IsNil()
+			resp := check.Send(context.Background(), time.Second)
+
+			g.Assert(resp.Err).IsNil()
+			g.Assert(resp.Reply).Equal([]byte("\x1c\x02status"))
+			g.Assert(resp.Trace.Total > 0).IsTrue()
+			g.Assert(check.Check(resp)).IsTrue()
+		})
+
+		g.It("Sends hex payload and matches reply bytes", func() {
+			check := &UDPCheck{
+				IntervalSec:  10,
+				TimeoutSec:   1,
+				Addr:         conn.LocalAddr().String(),
+				PayloadHex:   "e30004fa",
+				Expectations: []Expect{{Subject: "reply", ContainsHex: "1c02e3"}},
+			}
+			g.Assert(check.Validate()).IsNil()
+
+			g.Assert(check.Check(check.Send(context.Background(), time.Second))).IsTrue()
+
+			check.Expectations[0].ContainsHex = "ffff"
+			g.Assert(check.Check(check.Send(context.Background(), time.Second))).IsFalse()
+		})
+
+		g.It("Applies contains_hex together with string matchers", func() {
+			check := &UDPCheck{
+				IntervalSec:  10,
+				TimeoutSec:   1,
+				Addr:         conn.LocalAddr().String(),
+				Payload:      "status",
+				Expectations: []Expect{{Subject: "reply", ContainsHex: "1c02", Contains: "ready"}},
+			}
+			g.Assert(check.Validate()).IsNil()
+
+			g.Assert(check.Verify(check.Send(context.Background(), time.Second)).Error()).
+				Equal("Reply \"\\x1c\\x02status\" does not contain \"ready\"")
+
+			check.Expectations[0].Contains = "status"
+			g.Assert(check.Check(check.Send(context.Background(), time.Second))).IsTrue()
+
+			check.Expectations[0].Matches = "("
+			g.Assert(check.Validate().Error()).Equal("Invalid reply regexp: error parsing regexp: missing closing ): `(`")
+		})
+
+		g.It("Fails when there is no reply within timeout", func() {
+			check := &UDPCheck{
+				IntervalSec: 10,
+				TimeoutSec:  1,
+				Addr:        conn.LocalAddr().String(),
+				Payload:     "drop",
+			}
+			g.Assert(check.Validate()).IsNil()
+			resp := check.Send(context.Background(), 100*time.Millisecond)
+
+			g.Assert(resp.Err == nil).IsFalse()
+			g.Assert(check.Check(resp)).IsFalse()
+		})
+
+		g.It("Resolves address within context", func() {
+			check := &UDPCheck{
+				IntervalSec: 10,
+				TimeoutSec:  1,
+				Addr:        "ohdeer.invalid:123",
+				Payload:     "ping",
+			}
+			g.Assert(check.Validate()).IsNil()
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			resp := check.Send(ctx, time.Second)
+
+			g.Assert(resp.Err == nil).IsFalse()
+			g.Assert(resp.Trace.DNSLookup < 100*time.Millisecond).IsTrue()
+		})
+	})
+}
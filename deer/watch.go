@@ -0,0 +1,37 @@
+package deer
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// WatchConfig polls config file every interval and calls fn with freshly loaded config
+// whenever file modification time or size changes. Invalid config is reported via err.
+// Blocks until ctx is cancelled.
+func WatchConfig(ctx context.Context, path string, interval time.Duration, fn func(cfg *Config, err error)) {
+	last, _ := os.Stat(path)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			if last != nil {
+				fn(nil, err)
+			}
+			last = nil
+			continue
+		}
+		if last != nil && info.ModTime().Equal(last.ModTime()) && info.Size() == last.Size() {
+			continue
+		}
+		last = info
+
+		fn(LoadConfig(path))
+	}
+}
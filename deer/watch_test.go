@@ -0,0 +1,97 @@
+package deer
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/franela/goblin"
+)
+
+func TestWatchConfig(t *testing.T) {
+	g := goblin.Goblin(t)
+	g.Describe("WatchConfig", func() {
+		var (
+			dir  string
+			path string
+		)
+
+		write := func(src string, at time.Time) {
+			if err := ioutil.WriteFile(path, []byte(src), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chtimes(path, at, at); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		g.BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "ohdeer")
+			if err != nil {
+				t.Fatal(err)
+			}
+			path = filepath.Join(dir, "ohdeer.hcl")
+			write(`monitor "aws" { name = "AWS" }`, time.Now().Add(-time.Hour))
+		})
+
+		g.AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		watch := func() (chan *Config, chan error, context.CancelFunc) {
+			configs := make(chan *Config, 10)
+			errs := make(chan error, 10)
+			ctx, cancel := context.WithCancel(context.Background())
+			go WatchConfig(ctx, path, time.Millisecond, func(cfg *Config, err error) {
+				if err != nil {
+					errs <- err
+				} else {
+					configs <- cfg
+				}
+			})
+			// let watcher stat the file before it is changed
+			time.Sleep(20 * time.Millisecond)
+			return configs, errs, cancel
+		}
+
+		g.It("Loads config when file changes", func() {
+			configs, _, cancel := watch()
+			defer cancel()
+
+			write(`monitor "gcp" { name = "GCP" }`, time.Now())
+
+			select {
+			case cfg := <-configs:
+				g.Assert(cfg.Monitors[0].ID).Equal("gcp")
+			case <-time.After(time.Second):
+				g.Fail("Config not reloaded")
+			}
+		})
+
+		g.It("Reports invalid config", func() {
+			_, errs, cancel := watch()
+			defer cancel()
+
+			write(`monitor "gcp" {}`, time.Now())
+
+			select {
+			case err := <-errs:
+				g.Assert(err).IsNotNil()
+			case <-time.After(time.Second):
+				g.Fail("Error not reported")
+			}
+		})
+
+		g.It("Ignores untouched file", func() {
+			configs, errs, cancel := watch()
+			cancel()
+
+			g.Assert(len(configs)).Equal(0)
+			g.Assert(len(errs)).Equal(0)
+		})
+	})
+}
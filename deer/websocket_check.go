@@ -0,0 +1,206 @@
+package deer
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// WebSocketCheck defines websocket handshake and echo type check.
+type WebSocketCheck struct {
+	ref
+
+	// body
+	IntervalSec     float64  `hcl:"interval"`
+	TimeoutSec      uint64   `hcl:"timeout"`
+	JitterSec       uint64   `hcl:"jitter,optional"`
+	Retries         uint64   `hcl:"retries,optional"`
+	RetryDelaySec   uint64   `hcl:"retry_delay,optional"`
+	ConfirmFailures uint64   `hcl:"confirm_failures,optional"`
+	Addr            string   `hcl:"addr"`
+	Origin          string   `hcl:"origin,optional"`
+	Send            string   `hcl:"send,optional"`
+	Expectations    []Expect `hcl:"expect,block"`
+}
+
+// WebSocketResponse contains the result of the websocket check.
+type WebSocketResponse struct {
+	Err   error
+	Reply string
+	Trace Trace
+}
+
+// Validate ensures correct values are set for websocket check.
+func (w *WebSocketCheck) Validate() error {
+	if err := validateSchedule(w.IntervalSec, w.TimeoutSec, w.JitterSec, w.retryPolicy()); err != nil {
+		return err
+	}
+
+	if len(w.Addr) == 0 {
+		return fmt.Errorf("Addr cannot be empty")
+	}
+	u, err := url.Parse(w.Addr)
+	if err != nil || (u.Scheme != "ws" && u.Scheme != "wss") {
+		return fmt.Errorf("Addr must be ws:// or wss:// url")
+	}
+
+	for _, expect := range w.Expectations {
+		switch expect.Subject {
+		case "reply":
+			if w.Send == "" {
+				return fmt.Errorf("Reply expectation requires send")
+			}
+			if err := expect.validateMatcher("Reply"); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("Invalid expectation subject")
+		}
+	}
+
+	return nil
+}
+
+// Interval returns how often check should be run.
+func (w *WebSocketCheck) Interval() time.Duration {
+	return seconds(w.IntervalSec)
+}
+
+// Jitter returns maximum random delay added to each run.
+func (w *WebSocketCheck) Jitter() time.Duration {
+	return time.Duration(w.JitterSec) * time.Second
+}
+
+// target returns addr, from which check ID is derived.
+func (w *WebSocketCheck) target() string {
+	return w.Addr
+}
+
+// retryPolicy returns how failed probes are retried.
+func (w *WebSocketCheck) retryPolicy() retryPolicy {
+	return retryPolicy{w.Retries, w.RetryDelaySec, w.ConfirmFailures}
+}
+
+// RunFn returns task function to run check.
+func (w *WebSocketCheck) RunFn(s Store) func(context.Context) {
+	store := s
+
+	return func(ctx context.Context) {
+		w.ref.run(ctx, store, w.retryPolicy(), "", func() CheckResult {
+			now := time.Now()
+			resp := w.Dial(ctx, time.Duration(w.TimeoutSec)*time.Second)
+
+			result := w.ref.result(now)
+			result.Trace = &resp.Trace
+			result.verdict(w.Verify(resp))
+
+			return result
+		})
+	}
+}
+
+// Dial performs upgrade handshake and, when send is set, waits for the reply.
+func (w *WebSocketCheck) Dial(ctx context.Context, timeout time.Duration) *WebSocketResponse {
+	var resp WebSocketResponse
+
+	start := time.Now()
+	defer func() {
+		resp.Trace.Total = time.Since(start)
+	}()
+
+	u, err := url.Parse(w.Addr)
+	if err != nil {
+		resp.Err = err
+		return &resp
+	}
+	host, port := u.Hostname(), u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "wss" {
+			port = "443"
+		}
+	}
+
+	conn, err := dialTCP(ctx, "tcp", net.JoinHostPort(host, port), timeout, &resp.Trace)
+	if err != nil {
+		resp.Err = err
+		return &resp
+	}
+	defer conn.Close()
+	defer closeOnDone(ctx, conn)()
+
+	if err := conn.SetDeadline(start.Add(timeout)); err != nil {
+		resp.Err = err
+		return &resp
+	}
+
+	if u.Scheme == "wss" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: host})
+		started := time.Now()
+		err := tlsConn.Handshake()
+		resp.Trace.TLSHandshake = time.Since(started)
+		if err != nil {
+			resp.Err = err
+			return &resp
+		}
+		conn = tlsConn
+	}
+
+	origin := w.Origin
+	if origin == "" {
+		origin = "http://" + u.Host
+	}
+	cfg, err := websocket.NewConfig(w.Addr, origin)
+	if err != nil {
+		resp.Err = err
+		return &resp
+	}
+	cfg.Header.Set("User-Agent", DefaultUserAgent)
+
+	started := time.Now()
+	ws, err := websocket.NewClient(cfg, conn)
+	resp.Trace.WebSocketHandshake = time.Since(started)
+	if err != nil {
+		resp.Err = err
+		return &resp
+	}
+	defer ws.Close()
+
+	if w.Send != "" {
+		started := time.Now()
+		resp.Err = websocket.Message.Send(ws, w.Send)
+		if resp.Err == nil {
+			resp.Err = websocket.Message.Receive(ws, &resp.Reply)
+		}
+		resp.Trace.RoundTrip = time.Since(started)
+	}
+
+	return &resp
+}
+
+// Check verifies if check is valid or not.
+func (w *WebSocketCheck) Check(resp *WebSocketResponse) bool {
+	return w.Verify(resp) == nil
+}
+
+// Verify returns the reason why check is not valid or nil when it passes.
+func (w *WebSocketCheck) Verify(resp *WebSocketResponse) error {
+	if resp.Err != nil {
+		return resp.Err
+	}
+
+	for _, expect := range w.Expectations {
+		switch expect.Subject {
+		case "reply":
+			if err := expect.verifyString("Reply", resp.Reply); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package deer
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/franela/goblin"
+	"golang.org/x/net/websocket"
+)
+
+func TestWebSocketCheck(t *testing.T) {
+	g := goblin.Goblin(t)
+	g.Describe("WebSocketCheck", func() {
+		var srv *httptest.Server
+
+		g.Before(func() {
+			srv = httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+				var msg string
+				for websocket.Message.Receive(ws, &msg) == nil {
+					websocket.Message.Send(ws, "pong:"+msg)
+				}
+			}))
+		})
+
+		g.After(func() {
+			srv.Close()
+		})
+
+		addr := func() string {
+			return "ws" + strings.TrimPrefix(srv.URL, "http")
+		}
+
+		g.It("Performs handshake", func() {
+			check := &WebSocketCheck{Addr: addr()}
+			resp := check.Dial(context.Background(), time.Second)
+
+			g.Assert(check.Verify(resp)).IsNil()
+			g.Assert(resp.Trace.WebSocketHandshake > 0).IsTrue()
+		})
+
+		g.It("Sends message and matches reply", func() {
+			check := &WebSocketCheck{
+				Addr:         addr(),
+				Send:         "ping",
+				Expectations: []Expect{{Subject: "reply", Matches: "^pong:"}},
+			}
+			resp := check.Dial(context.Background(), time.Second)
+
+			g.Assert(check.Verify(resp)).IsNil()
+			g.Assert(resp.Reply).Equal("pong:ping")
+		})
+
+		g.It("Fails when server is not a websocket", func() {
+			plain := httptest.NewServer(nil)
+			defer plain.Close()
+
+			check := &WebSocketCheck{Addr: "ws" + strings.TrimPrefix(plain.URL, "http")}
+
+			g.Assert(check.Check(check.Dial(context.Background(), time.Second))).IsFalse()
+		})
+	})
+}
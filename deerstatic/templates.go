@@ -27,8 +27,8 @@ const IndexTpl = `
                         {{range .Services}}
                             <li class="list-group-item" data-service="{{.ID}}" data-monitor="{{$MonitorID}}">
                                 <strong>{{.Name}}</strong>
-								<p class="buttons text-center">
-								</p>
+								<div class="buttons-list">
+								</div>
 								<div class="charts text-center">
 									<div class="chart-1">
 									</div>
@@ -62,20 +62,39 @@ $(function() {
 			m.services.forEach(function(s) {
 				const M = m.id;
 				const S = s.id;
-				$.get("/api/v1/metrics/default/"+M+"/"+S, {}, function(result) {
-					const li = $("li[data-monitor='"+M+"'][data-service='"+S+"']");
-					const b = li.find(".buttons");
-					b.text(result.uptime);
-					result.metrics.forEach(function(m) {
-						var bt = $("<button>").attr("type", "button").data("when", m.bucket);
-						if (m.health === 1.0) {
-							bt.addClass("clickable btn btn-success");
-						} else if (m.health === -1) {
-							bt.addClass("btn btn-secondary");
+				const li = $("li[data-monitor='"+M+"'][data-service='"+S+"']");
+				const versions = s.ip_versions || [""];
+				versions.forEach(function(V) {
+					const b = $("<p>").addClass("buttons text-center").data("ip-version", V);
+					li.find(".buttons-list").append(b);
+					$.get("/api/v1/metrics/default/"+M+"/"+S, { ip_version: V }, function(result) {
+						if (V !== "") {
+							b.text("IPv" + V + ": " + result.uptime);
 						} else {
-							bt.addClass("btn btn-danger clickable");
+							b.text(result.uptime);
+						}
+						var certDaysLeft = null;
+						result.metrics.forEach(function(m) {
+							if (m.details.tls) {
+								certDaysLeft = m.details.tls.days_left;
+							}
+						});
+						if (certDaysLeft !== null) {
+							b.append($("<small>").addClass("d-block text-muted").text("Certificate expires in " + certDaysLeft + " days"));
 						}
-						b.append(bt);
+						result.metrics.forEach(function(m) {
+							var bt = $("<button>").attr("type", "button").data("when", m.bucket);
+							if (m.health === 1.0 && m.degraded_checks > 0) {
+								bt.addClass("clickable btn btn-warning");
+							} else if (m.health === 1.0) {
+								bt.addClass("clickable btn btn-success");
+							} else if (m.health === -1) {
+								bt.addClass("btn btn-secondary");
+							} else {
+								bt.addClass("btn btn-danger clickable");
+							}
+							b.append(bt);
+						});
 					});
 				});
 			})
@@ -92,8 +111,9 @@ $(function() {
 		chart1.html(spinner);
 		const M = li.data("monitor");
 		const S = li.data("service");
+		const V = $(this).closest(".buttons").data("ip-version");
 
-		$.get("/api/v1/metrics/details/"+M+"/"+S, { since: when }, function(result) {
+		$.get("/api/v1/metrics/details/"+M+"/"+S, { since: when, ip_version: V }, function(result) {
 			self.text(result.uptime);
 			chart1.html("");
 			chart2.html("");
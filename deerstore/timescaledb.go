@@ -80,10 +80,18 @@ func (m *TimescaleDB) Close(ctx context.Context) {
 // Save inserts metrics to database.
 func (m *TimescaleDB) Save(ctx context.Context, result *deer.CheckResult) {
 	var d deer.Details
+	if result.Details != nil {
+		d = *result.Details
+	}
 	d.Trace = result.Trace
+	d.Degraded = result.Degraded
+	d.IPVersion = result.IPVersion
 
 	if result.StatusCode != 0 {
-		d.Response = &deer.ResponseDetails{StatusCode: result.StatusCode}
+		if d.Response == nil {
+			d.Response = &deer.ResponseDetails{}
+		}
+		d.Response.StatusCode = result.StatusCode
 	}
 
 	if result.Error != nil {
@@ -133,6 +141,11 @@ func (m *TimescaleDB) Read(ctx context.Context, filter *deer.ReadFilter) ([]*dee
 	if sb.Len() == 0 {
 		sb.WriteString("1=1")
 	}
+	where := "(" + sb.String() + ")"
+	if filter.IPVersion != "" {
+		// only http checks are run per ip version, results of other checks count for every version
+		where += " AND (details->>'ip_version' = " + pq.QuoteLiteral(filter.IPVersion) + " OR details->>'ip_version' IS NULL)"
+	}
 	sql := fmt.Sprintf(
 		metricsSQL,
 		pq.QuoteLiteral(bucket),
@@ -140,7 +153,7 @@ func (m *TimescaleDB) Read(ctx context.Context, filter *deer.ReadFilter) ([]*dee
 		pq.QuoteLiteral(intervalStop.Format(time.RFC3339)),
 		pq.QuoteLiteral(intervalStart.Format(time.RFC3339)),
 		pq.QuoteLiteral(intervalStop.Format(time.RFC3339)),
-		sb.String(),
+		where,
 	)
 
 	// fmt.Println(sql)
@@ -153,6 +166,7 @@ func (m *TimescaleDB) Read(ctx context.Context, filter *deer.ReadFilter) ([]*dee
 
 	var (
 		dnsLookup, tcpConnection, tlsHandshake, serverProcessing, contentTransfer, total *float64
+		certDaysLeft                                                                     *int
 	)
 
 	for rows.Next() {
@@ -166,12 +180,14 @@ func (m *TimescaleDB) Read(ctx context.Context, filter *deer.ReadFilter) ([]*dee
 			&metric.Health,
 			&metric.PassedChecks,
 			&metric.FailedChecks,
+			&metric.DegradedChecks,
 			&dnsLookup,
 			&tcpConnection,
 			&tlsHandshake,
 			&serverProcessing,
 			&contentTransfer,
 			&total,
+			&certDaysLeft,
 		); err != nil {
 			return nil, err
 		}
@@ -194,6 +210,9 @@ func (m *TimescaleDB) Read(ctx context.Context, filter *deer.ReadFilter) ([]*dee
 		if total != nil {
 			metric.Details.Trace.Total = time.Duration(*total) / unit
 		}
+		if certDaysLeft != nil {
+			metric.Details.TLS = &deer.TLSDetails{DaysLeft: *certDaysLeft}
+		}
 		res = append(res, &metric)
 	}
 
@@ -208,12 +227,14 @@ SELECT
   COALESCE(count(*) FILTER (WHERE success IS true) / count(*)::numeric, -1) AS health,
   COALESCE(count(*) FILTER (WHERE success IS true), 0) AS passed_checks,
   COALESCE(count(*) FILTER (WHERE success IS false), 0) AS failed_checks,
+  COALESCE(count(*) FILTER (WHERE (details->>'degraded')::bool IS true), 0) AS degraded_checks,
   AVG((details->'trace'->>'dns_lookup')::numeric) AS dns_lookup,
   AVG((details->'trace'->>'tcp_connection')::numeric) AS tcp_connection,
   AVG((details->'trace'->>'tls_handshake')::numeric) AS tls_handshake,
   AVG((details->'trace'->>'server_processing')::numeric) AS server_processing,
   AVG((details->'trace'->>'content_transfer')::numeric) AS content_transfer,
-  AVG((details->'trace'->>'total')::numeric) AS total
+  AVG((details->'trace'->>'total')::numeric) AS total,
+  MIN((details->'tls'->>'days_left')::int) AS cert_days_left
 FROM metrics
 WHERE (at BETWEEN %s AND %s) AND %s
 GROUP BY monitor_id, service_id, bucket
@@ -5,9 +5,8 @@ go 1.15
 require (
 	github.com/franela/goblin v0.0.0-20201006155558-6240afcb2eb7
 	github.com/getsentry/sentry-go v0.8.0
-	github.com/google/go-cmp v0.5.0 // indirect
+	github.com/go-sql-driver/mysql v1.5.0
 	github.com/hashicorp/hcl/v2 v2.7.0
-	github.com/jasonlvhit/gocron v0.0.1
 	github.com/kr/pretty v0.2.0 // indirect
 	github.com/labstack/echo/v4 v4.1.11
 	github.com/labstack/gommon v0.3.0
@@ -18,8 +17,9 @@ require (
 	github.com/stretchr/testify v1.6.1 // indirect
 	github.com/valyala/fasttemplate v1.2.1 // indirect
 	golang.org/x/crypto v0.0.0-20201016220609-9e8e0b390897 // indirect
-	golang.org/x/net v0.0.0-20201031054903-ff519b6c9102 // indirect
+	golang.org/x/net v0.0.0-20201031054903-ff519b6c9102
 	golang.org/x/sys v0.0.0-20201101102859-da207088b7d1 // indirect
 	golang.org/x/text v0.3.4 // indirect
+	google.golang.org/grpc v1.33.2
 	gopkg.in/check.v1 v1.0.0-20190902080502-41f04d3bba15 // indirect
 )
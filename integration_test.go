@@ -120,5 +120,56 @@ func TestRunner(t *testing.T) {
 				g.Assert(d.Hours()).Eql(1.0)
 			}
 		})
+
+		g.It("Keeps results without ip version when filtering by ip version", func() {
+			store, err := deerstore.NewTimescaleDB(context.Background(), os.Getenv("DATABASE_URL"))
+			if err != nil {
+				t.Errorf("Error openning database %v", err)
+				return
+			}
+			defer store.Close(context.Background())
+
+			if err := store.Migrate(context.Background()); err != nil {
+				t.Errorf("Error migrating database %v", err)
+				return
+			}
+			if err := store.Truncate(context.Background()); err != nil {
+				t.Errorf("Error truncating database %v", err)
+				return
+			}
+
+			at := time.Now().Add(-time.Minute)
+			for _, result := range []*deer.CheckResult{
+				{MonitorID: "test", ServiceID: "api", At: at, Success: true, IPVersion: "4"},
+				{MonitorID: "test", ServiceID: "api", At: at, Success: false, IPVersion: "6"},
+				{MonitorID: "test", ServiceID: "api", At: at, Success: true},
+			} {
+				store.Save(context.Background(), result)
+			}
+
+			for ipVersion, passed := range map[string]uint64{"4": 2, "6": 1} {
+				metrics, err := store.Read(context.Background(), &deer.ReadFilter{
+					Since:          at.Add(-time.Hour),
+					TimeBucket:     1,
+					TimeBucketUnit: "hour",
+					Interval:       2,
+					IntervalUnit:   "hour",
+					ActiveServices: map[string][]string{"test": []string{"api"}},
+					IPVersion:      ipVersion,
+				})
+				if err != nil {
+					t.Errorf("Error fetching data %v", err)
+					return
+				}
+
+				var total, totalPassed uint64
+				for _, m := range metrics {
+					total += m.PassedChecks + m.FailedChecks
+					totalPassed += m.PassedChecks
+				}
+				g.Assert(total).Eql(uint64(2))
+				g.Assert(totalPassed).Eql(passed)
+			}
+		})
 	})
 }
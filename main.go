@@ -8,6 +8,8 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/getsentry/sentry-go"
@@ -21,6 +23,9 @@ import (
 	"github.com/qbart/ohtea/tea"
 )
 
+// heartbeatTokenPath is heartbeat route for clients which cannot set Authorization header.
+const heartbeatTokenPath = "/api/v1/heartbeat/:monitor/:service/:token"
+
 func main() {
 	configPath := flag.String("C", "./ohdeer.hcl", "config file path")
 	flag.Parse()
@@ -28,7 +33,12 @@ func main() {
 	e := echo.New()
 	e.HideBanner = true
 	e.Use(middleware.Recover())
-	e.Use(middleware.Logger())
+	e.Use(middleware.LoggerWithConfig(middleware.LoggerConfig{
+		// token passed in the path must not end up in logs
+		Skipper: func(c echo.Context) bool {
+			return c.Path() == heartbeatTokenPath
+		},
+	}))
 	e.Use(middleware.Secure())
 	e.Logger.SetLevel(log.INFO)
 
@@ -59,19 +69,21 @@ func main() {
 		e.Logger.Fatal(err)
 	}
 
+	runner := deer.NewRunner(cfg, store)
+
 	e.Renderer = &myTemplate{
 		templates: template.Must(template.New("index").Parse(deerstatic.IndexTpl)),
 	}
 	e.Logger.Info("Starting server")
 	e.GET("/", func(c echo.Context) error {
-		if err = c.Render(http.StatusOK, "index", cfg); err != nil {
+		if err := c.Render(http.StatusOK, "index", runner.Config()); err != nil {
 			e.Logger.Error(err)
 			return err
 		}
 		return nil
 	})
 	e.GET("/api/v1/config", func(c echo.Context) error {
-		return c.JSON(http.StatusOK, buildConfigResp(cfg))
+		return c.JSON(http.StatusOK, buildConfigResp(runner.Config()))
 	})
 	e.GET("/api/v1/metrics/default/:monitor/:service", func(c echo.Context) error {
 		active := activeFilter(c.Param("monitor"), c.Param("service"))
@@ -84,6 +96,7 @@ func main() {
 			Interval:       89,
 			IntervalUnit:   "day",
 			ActiveServices: active,
+			IPVersion:      c.QueryParam("ip_version"),
 		})
 
 		if err != nil {
@@ -109,6 +122,7 @@ func main() {
 			Interval:       1,
 			IntervalUnit:   "day",
 			ActiveServices: active,
+			IPVersion:      c.QueryParam("ip_version"),
 		})
 
 		if err != nil {
@@ -121,6 +135,26 @@ func main() {
 		})
 	})
 
+	heartbeat := func(c echo.Context) error {
+		token := c.Param("token")
+		if auth := c.Request().Header.Get(echo.HeaderAuthorization); strings.HasPrefix(auth, "Bearer ") {
+			token = strings.TrimPrefix(auth, "Bearer ")
+		}
+
+		err := runner.Ping(c.Request().Context(), c.Param("monitor"), c.Param("service"), token)
+		if err == deer.ErrHeartbeatNotFound {
+			return c.String(http.StatusNotFound, err.Error())
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+	e.GET("/api/v1/runner/stats", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, runner.Stats())
+	})
+	e.GET("/api/v1/heartbeat/:monitor/:service", heartbeat)
+	e.POST("/api/v1/heartbeat/:monitor/:service", heartbeat)
+	e.GET(heartbeatTokenPath, heartbeat)
+	e.POST(heartbeatTokenPath, heartbeat)
+
 	go func() {
 		var err error
 		if cfg.IsTLSConfigured() {
@@ -137,14 +171,39 @@ func main() {
 	}()
 
 	e.Logger.Info("Starting jobs")
-	runner := deer.NewRunner(cfg, store)
 	go runner.Start(context.Background())
 
+	reload := func(next *deer.Config, err error) {
+		if err != nil {
+			e.Logger.Errorf("Config not reloaded: %v", err)
+			return
+		}
+		if *next.Server != *runner.Config().Server {
+			e.Logger.Warn("Server config changed, restart is required to apply it")
+		}
+		stats := runner.Reload(next)
+		e.Logger.Infof("Config reloaded: %d added, %d removed, %d updated checks", stats.Added, stats.Removed, stats.Updated)
+	}
+	watchCtx, stopWatching := context.WithCancel(context.Background())
+	go deer.WatchConfig(watchCtx, *configPath, 5*time.Second, reload)
+
 	loop := tea.NewLoop()
+	loop.QuitSignals = append(loop.QuitSignals, syscall.SIGHUP)
+	loop.OnQuit(func(sig os.Signal) bool {
+		if sig == syscall.SIGHUP {
+			e.Logger.Info("Reloading config")
+			reload(deer.LoadConfig(*configPath))
+			return false
+		}
+		return true
+	})
 	loop.OnShutdown(func(ctx context.Context) {
+		stopWatching()
 
 		e.Logger.Info("Shutting down the runner")
-		runner.Shutdown(ctx)
+		if err := runner.Shutdown(ctx); err != nil {
+			e.Logger.Error(err)
+		}
 		e.Logger.Info("Shutting down the server")
 		if err := e.Shutdown(ctx); err != nil {
 			e.Logger.Fatal(err)
@@ -189,8 +248,9 @@ type configMonitorResp struct {
 	Services []configServiceResp `json:"services"`
 }
 type configServiceResp struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	IPVersions []string `json:"ip_versions,omitempty"`
 }
 
 func buildConfigResp(cfg *deer.Config) *configResp {
@@ -207,6 +267,9 @@ func buildConfigResp(cfg *deer.Config) *configResp {
 				ID:   s.ID,
 				Name: s.Name,
 			}
+			if versions := s.IPVersions(); len(versions) > 1 {
+				r.Monitors[mi].Services[si].IPVersions = versions
+			}
 		}
 	}
 